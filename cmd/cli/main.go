@@ -1,11 +1,23 @@
 package main
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/rpc"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/events"
+	"github.com/blennster/gonnect/internal/plugins"
+	gonnectrpc "github.com/blennster/gonnect/internal/rpc"
 )
 
 func setupLogger() {
@@ -21,8 +33,169 @@ func setupLogger() {
 var (
 	deviceCmd = flag.NewFlagSet("", flag.ExitOnError)
 	device    = deviceCmd.String("device", "", "device to operate on")
+
+	sendFileCmd    = flag.NewFlagSet("send-file", flag.ExitOnError)
+	sendFileDevice = sendFileCmd.String("device", "", "device to send the files to")
+
+	notifyCmd    = flag.NewFlagSet("notify", flag.ExitOnError)
+	notifyDevice = notifyCmd.String("device", "", "device to send the notification to")
+	notifyTitle  = notifyCmd.String("title", "", "title of the notification")
+	notifyText   = notifyCmd.String("text", "", "text of the notification")
+	notifyApp    = notifyCmd.String("app", "", "app name shown on the notification")
+
+	notifyReplyCmd    = flag.NewFlagSet("notify-reply", flag.ExitOnError)
+	notifyReplyDevice = notifyReplyCmd.String("device", "", "device the notification is on")
+	notifyReplyId     = notifyReplyCmd.String("id", "", "id of the notification")
+	notifyReplyText   = notifyReplyCmd.String("text", "", "reply to send")
+
+	notifyActionCmd    = flag.NewFlagSet("notify-action", flag.ExitOnError)
+	notifyActionDevice = notifyActionCmd.String("device", "", "device the notification is on")
+	notifyActionId     = notifyActionCmd.String("id", "", "id of the notification")
+	notifyActionName   = notifyActionCmd.String("action", "", "name of the notification button to press")
+
+	notificationsListCmd    = flag.NewFlagSet("notifications list", flag.ExitOnError)
+	notificationsListDevice = notificationsListCmd.String("device", "", "device to list notifications of")
+	notificationsListApp    = notificationsListCmd.String("app", "", "only list notifications from this app")
+
+	notificationsDismissCmd    = flag.NewFlagSet("notifications dismiss", flag.ExitOnError)
+	notificationsDismissDevice = notificationsDismissCmd.String("device", "", "device the notification is on")
+	notificationsDismissId     = notificationsDismissCmd.String("id", "", "id of the notification")
+
+	commandsAddCmd     = flag.NewFlagSet("commands add", flag.ExitOnError)
+	commandsAddName    = commandsAddCmd.String("name", "", "name shown on the device")
+	commandsAddCommand = commandsAddCmd.String("command", "", "shell command to run")
+
+	commandsRemoveCmd = flag.NewFlagSet("commands remove", flag.ExitOnError)
+	commandsRemoveKey = commandsRemoveCmd.String("id", "", "id of the command to remove")
+
+	mediaCmd    = flag.NewFlagSet("media", flag.ExitOnError)
+	mediaDevice = mediaCmd.String("device", "", "device to control")
+	mediaPlayer = mediaCmd.String("player", "", "player to control, defaults to the first one")
+
+	smsListCmd    = flag.NewFlagSet("sms list", flag.ExitOnError)
+	smsListDevice = smsListCmd.String("device", "", "device to list conversations of")
+
+	smsShowCmd    = flag.NewFlagSet("sms show", flag.ExitOnError)
+	smsShowDevice = smsShowCmd.String("device", "", "device the conversation is on")
+	smsShowThread = smsShowCmd.Int64("thread", -1, "id of the conversation, as shown by sms list")
+
+	smsSendCmd    = flag.NewFlagSet("sms send", flag.ExitOnError)
+	smsSendDevice = smsSendCmd.String("device", "", "device to send the message from")
+	smsSendTo     = smsSendCmd.String("to", "", "phone number to send the message to")
+	smsSendText   = smsSendCmd.String("text", "", "text of the message")
+
+	contactsSearchCmd    = flag.NewFlagSet("contacts search", flag.ExitOnError)
+	contactsSearchDevice = contactsSearchCmd.String("device", "", "device to search the contacts of")
+
+	contactsExportCmd    = flag.NewFlagSet("contacts export", flag.ExitOnError)
+	contactsExportDevice = contactsExportCmd.String("device", "", "device to export the contacts of")
+	contactsExportOutput = contactsExportCmd.String("output", "", "file to write the vcards to, defaults to stdout")
+
+	pingCmd     = flag.NewFlagSet("ping", flag.ExitOnError)
+	pingDevice  = pingCmd.String("device", "", "device to ping")
+	pingMessage = pingCmd.String("message", "", "message shown on the device")
+	pingLatency = pingCmd.Int("latency", 0, "measure how long this many pings take to be acknowledged instead")
+
+	typeCmd    = flag.NewFlagSet("type", flag.ExitOnError)
+	typeDevice = typeCmd.String("device", "", "device to type on")
+	typeKey    = typeCmd.String("key", "", "press a single key instead, such as enter, f5 or ctrl+a")
+
+	eventsCmd    = flag.NewFlagSet("events", flag.ExitOnError)
+	eventsFollow = eventsCmd.Bool("follow", false, "keep waiting for new events")
 )
 
+// Poll the server for the progress of each transfer until all of them are done
+func followTransfers(client *rpc.Client, ids []string) bool {
+	ok := true
+	for _, id := range ids {
+		var status plugins.TransferStatus
+		for {
+			err := client.Call("GonnectRpc.GetTransfer", id, &status)
+			if err != nil {
+				panic(err)
+			}
+
+			percent := 100
+			if status.Size > 0 {
+				percent = int(status.Transferred * 100 / status.Size)
+			}
+			fmt.Printf("\r%s: %d/%d bytes (%d%%)", filepath.Base(status.Path), status.Transferred, status.Size, percent)
+
+			if status.Done {
+				break
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		if status.Err != "" {
+			fmt.Printf(" failed: %s\n", status.Err)
+			ok = false
+		} else {
+			fmt.Println(" done")
+		}
+	}
+
+	return ok
+}
+
+func printRemotePlayer(p plugins.RemotePlayer) {
+	state := "paused"
+	position := time.Duration(p.Pos) * time.Millisecond
+	if p.IsPlaying {
+		state = "playing"
+		// The position was only correct when the state was received
+		position += time.Since(p.Updated)
+	}
+	if p.Length > 0 {
+		position = min(position, time.Duration(p.Length)*time.Millisecond)
+	}
+
+	fmt.Printf("%s (%s)\n", p.Player, state)
+	if p.Title != "" {
+		fmt.Printf("  %s - %s\n", p.Artist, p.Title)
+		fmt.Printf("  %s / %s\n", position.Truncate(time.Second), (time.Duration(p.Length) * time.Millisecond).Truncate(time.Second))
+	}
+	fmt.Printf("  volume %d%%\n", p.Volume)
+	if p.AlbumArtPath != "" {
+		fmt.Printf("  album art %s\n", p.AlbumArtPath)
+	}
+}
+
+func smsTime(m internal.GonnectSmsMessage) string {
+	return time.UnixMilli(m.Date).Format(time.DateTime)
+}
+
+// Show the addresses of a message with the names of the contacts
+func smsAddresses(m internal.GonnectSmsMessage, names map[string]string) string {
+	addresses := make([]string, 0, len(m.Addresses))
+	for _, a := range m.Addresses {
+		if name, ok := names[a.Address]; ok && name != a.Address {
+			addresses = append(addresses, fmt.Sprintf("%s (%s)", name, a.Address))
+			continue
+		}
+		addresses = append(addresses, a.Address)
+	}
+	return strings.Join(addresses, ", ")
+}
+
+// Look up the contact names of the addresses in messages, the numbers are
+// shown as they are if this fails
+func contactNames(client *rpc.Client, device string, messages []internal.GonnectSmsMessage) map[string]string {
+	args := gonnectrpc.ContactNamesArgs{Device: device}
+	for _, m := range messages {
+		for _, a := range m.Addresses {
+			args.Numbers = append(args.Numbers, a.Address)
+		}
+	}
+
+	var names map[string]string
+	err := client.Call("GonnectRpc.GetContactNames", args, &names)
+	if err != nil {
+		slog.Warn("failed to look up contact names", "err", err)
+	}
+	return names
+}
+
 func main() {
 	client, err := rpc.DialHTTP("unix", "/tmp/gonnect.sock")
 	if err != nil {
@@ -32,7 +205,7 @@ func main() {
 
 	if len(os.Args) < 2 {
 		fmt.Println("no command specified")
-		fmt.Println("available commands: pair, unpair, get")
+		fmt.Println("available commands: pair, unpair, list, send-file, notify, notify-reply, notify-action, notifications, battery, commands, input, media, sms, contacts, lock, locked, mute-ringer, ping, ring, stop-ringing, type, events")
 		os.Exit(1)
 	}
 
@@ -82,5 +255,679 @@ func main() {
 			fmt.Println(name)
 		}
 		return
+	case "send-file":
+		sendFileCmd.Parse(os.Args[2:])
+		if *sendFileDevice != "" && sendFileCmd.NArg() > 0 {
+			args := gonnectrpc.SendFileArgs{Device: *sendFileDevice}
+			for _, path := range sendFileCmd.Args() {
+				abs, err := filepath.Abs(path)
+				if err != nil {
+					panic(err)
+				}
+				args.Paths = append(args.Paths, abs)
+			}
+
+			var reply []string
+			err = client.Call("GonnectRpc.SendFile", args, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			if !followTransfers(client, reply) {
+				os.Exit(1)
+			}
+			return
+		}
+
+		fmt.Println("Usage: send-file --device <id> <paths...>")
+		sendFileCmd.PrintDefaults()
+		os.Exit(1)
+	case "notify":
+		notifyCmd.Parse(os.Args[2:])
+		if *notifyDevice != "" && (*notifyTitle != "" || *notifyText != "") {
+			var reply string
+			args := gonnectrpc.SendNotificationArgs{Device: *notifyDevice, App: *notifyApp, Title: *notifyTitle, Text: *notifyText}
+			err = client.Call("GonnectRpc.SendNotification", args, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(reply)
+			return
+		}
+
+		fmt.Println("Usage:")
+		notifyCmd.PrintDefaults()
+		os.Exit(1)
+	case "notify-reply":
+		notifyReplyCmd.Parse(os.Args[2:])
+		if *notifyReplyDevice != "" && *notifyReplyId != "" && *notifyReplyText != "" {
+			var reply string
+			args := gonnectrpc.NotificationArgs{Device: *notifyReplyDevice, Id: *notifyReplyId, Text: *notifyReplyText}
+			err = client.Call("GonnectRpc.NotifyReply", args, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(reply)
+			return
+		}
+
+		fmt.Println("Usage:")
+		notifyReplyCmd.PrintDefaults()
+		os.Exit(1)
+	case "notify-action":
+		notifyActionCmd.Parse(os.Args[2:])
+		if *notifyActionDevice != "" && *notifyActionId != "" && *notifyActionName != "" {
+			var reply string
+			args := gonnectrpc.NotificationArgs{Device: *notifyActionDevice, Id: *notifyActionId, Action: *notifyActionName}
+			err = client.Call("GonnectRpc.NotifyAction", args, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(reply)
+			return
+		}
+
+		fmt.Println("Usage:")
+		notifyActionCmd.PrintDefaults()
+		os.Exit(1)
+	case "notifications":
+		sub := ""
+		if len(os.Args) > 2 {
+			sub = os.Args[2]
+		}
+
+		switch sub {
+		case "list":
+			notificationsListCmd.Parse(os.Args[3:])
+			if *notificationsListDevice != "" {
+				var reply []plugins.NotificationRecord
+				args := gonnectrpc.NotificationQuery{Device: *notificationsListDevice, App: *notificationsListApp}
+				err = client.Call("GonnectRpc.GetNotifications", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				for _, n := range reply {
+					state := ""
+					if n.Dismissed {
+						state = " (dismissed)"
+					}
+					fmt.Printf("%s [%s] %s: %s - %s%s\n", n.Time.Format(time.DateTime), n.Id, n.AppName, n.Title, n.Text, state)
+				}
+				return
+			}
+
+			fmt.Println("Usage:")
+			notificationsListCmd.PrintDefaults()
+			os.Exit(1)
+		case "dismiss":
+			notificationsDismissCmd.Parse(os.Args[3:])
+			if *notificationsDismissDevice != "" && *notificationsDismissId != "" {
+				var reply string
+				args := gonnectrpc.NotificationArgs{Device: *notificationsDismissDevice, Id: *notificationsDismissId}
+				err = client.Call("GonnectRpc.DismissNotification", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Println(reply)
+				return
+			}
+
+			fmt.Println("Usage:")
+			notificationsDismissCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		fmt.Println("Usage: notifications <list|dismiss>")
+		os.Exit(1)
+	case "battery":
+		deviceCmd.Parse(os.Args[2:])
+		if *device != "" {
+			var reply plugins.BatteryStatus
+			err = client.Call("GonnectRpc.GetBattery", *device, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			state := "discharging"
+			if reply.IsCharging {
+				state = "charging"
+			}
+			if reply.Low {
+				state += ", low"
+			}
+			fmt.Printf("%d%% (%s), updated %s\n", reply.Charge, state, reply.Updated.Format(time.DateTime))
+			return
+		}
+
+		fmt.Println("Usage:")
+		deviceCmd.PrintDefaults()
+		os.Exit(1)
+	case "commands":
+		sub := ""
+		if len(os.Args) > 2 {
+			sub = os.Args[2]
+		}
+
+		switch sub {
+		case "list":
+			var reply map[string]config.Command
+			err = client.Call("GonnectRpc.GetCommands", struct{}{}, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			for key, command := range reply {
+				fmt.Printf("%s %s: %s\n", key, command.Name, command.Command)
+			}
+			return
+		case "add":
+			commandsAddCmd.Parse(os.Args[3:])
+			if *commandsAddName != "" && *commandsAddCommand != "" {
+				var reply string
+				args := config.Command{Name: *commandsAddName, Command: *commandsAddCommand}
+				err = client.Call("GonnectRpc.AddCommand", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Printf("added command with id %s\n", reply)
+				return
+			}
+
+			fmt.Println("Usage:")
+			commandsAddCmd.PrintDefaults()
+			os.Exit(1)
+		case "remove":
+			commandsRemoveCmd.Parse(os.Args[3:])
+			if *commandsRemoveKey != "" {
+				var reply string
+				err = client.Call("GonnectRpc.RemoveCommand", *commandsRemoveKey, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Println(reply)
+				return
+			}
+
+			fmt.Println("Usage:")
+			commandsRemoveCmd.PrintDefaults()
+			os.Exit(1)
+		case "allow", "deny":
+			deviceCmd.Parse(os.Args[3:])
+			if *device != "" {
+				var reply string
+				args := gonnectrpc.PermissionArgs{Device: *device, Allowed: sub == "allow"}
+				err = client.Call("GonnectRpc.SetRunCommandPermission", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Println(reply)
+				return
+			}
+
+			fmt.Println("Usage:")
+			deviceCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		fmt.Println("Usage: commands <list|add|remove|allow|deny>")
+		os.Exit(1)
+	case "input":
+		sub := ""
+		if len(os.Args) > 2 {
+			sub = os.Args[2]
+		}
+
+		switch sub {
+		case "allow", "deny":
+			deviceCmd.Parse(os.Args[3:])
+			if *device != "" {
+				var reply string
+				args := gonnectrpc.PermissionArgs{Device: *device, Allowed: sub == "allow"}
+				err = client.Call("GonnectRpc.SetRemoteInputPermission", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Println(reply)
+				return
+			}
+
+			fmt.Println("Usage:")
+			deviceCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		fmt.Println("Usage: input <allow|deny>")
+		os.Exit(1)
+	case "media":
+		mediaCmd.Parse(os.Args[2:])
+		if *mediaDevice == "" {
+			fmt.Println("Usage: media --device <id> [--player <name>] [players|status|play-pause|next|prev|seek <seconds>|volume <percent>]")
+			mediaCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		command := "status"
+		if mediaCmd.NArg() > 0 {
+			command = mediaCmd.Arg(0)
+		}
+
+		switch command {
+		case "players", "status":
+			var reply []plugins.RemotePlayer
+			err = client.Call("GonnectRpc.GetMediaPlayers", *mediaDevice, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			for _, p := range reply {
+				if command == "players" {
+					fmt.Println(p.Player)
+					continue
+				}
+				if *mediaPlayer != "" && p.Player != *mediaPlayer {
+					continue
+				}
+				printRemotePlayer(p)
+			}
+			return
+		}
+
+		args := gonnectrpc.MediaArgs{Device: *mediaDevice, Player: *mediaPlayer, Command: command}
+		if command == "seek" || command == "volume" {
+			if mediaCmd.NArg() < 2 {
+				fmt.Printf("%s needs a value\n", command)
+				os.Exit(1)
+			}
+			args.Value, err = strconv.ParseInt(mediaCmd.Arg(1), 10, 64)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		var reply string
+		err = client.Call("GonnectRpc.ControlMedia", args, &reply)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	case "sms":
+		sub := ""
+		if len(os.Args) > 2 {
+			sub = os.Args[2]
+		}
+
+		switch sub {
+		case "list":
+			smsListCmd.Parse(os.Args[3:])
+			if *smsListDevice != "" {
+				var reply []internal.GonnectSmsMessage
+				err = client.Call("GonnectRpc.GetConversations", *smsListDevice, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				names := contactNames(client, *smsListDevice, reply)
+				for _, m := range reply {
+					fmt.Printf("%s [%d] %s: %s\n", smsTime(m), m.ThreadId, smsAddresses(m, names), m.Body)
+				}
+				return
+			}
+
+			fmt.Println("Usage:")
+			smsListCmd.PrintDefaults()
+			os.Exit(1)
+		case "show":
+			smsShowCmd.Parse(os.Args[3:])
+			if *smsShowDevice != "" && *smsShowThread >= 0 {
+				var reply []internal.GonnectSmsMessage
+				args := gonnectrpc.ConversationArgs{Device: *smsShowDevice, Thread: *smsShowThread}
+				err = client.Call("GonnectRpc.GetConversation", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				names := contactNames(client, *smsShowDevice, reply)
+				for _, m := range reply {
+					from := smsAddresses(m, names)
+					if m.MessageType == internal.SmsSent {
+						from = "me"
+					}
+					fmt.Printf("%s %s: %s\n", smsTime(m), from, m.Body)
+				}
+				return
+			}
+
+			fmt.Println("Usage:")
+			smsShowCmd.PrintDefaults()
+			os.Exit(1)
+		case "send":
+			smsSendCmd.Parse(os.Args[3:])
+			if *smsSendDevice != "" && *smsSendTo != "" && *smsSendText != "" {
+				var reply string
+				args := gonnectrpc.SmsArgs{Device: *smsSendDevice, To: *smsSendTo, Text: *smsSendText}
+				err = client.Call("GonnectRpc.SendSms", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Println(reply)
+				return
+			}
+
+			fmt.Println("Usage:")
+			smsSendCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		fmt.Println("Usage: sms <list|show|send>")
+		os.Exit(1)
+	case "contacts":
+		sub := ""
+		if len(os.Args) > 2 {
+			sub = os.Args[2]
+		}
+
+		switch sub {
+		case "search":
+			contactsSearchCmd.Parse(os.Args[3:])
+			if *contactsSearchDevice != "" && contactsSearchCmd.NArg() > 0 {
+				var reply []plugins.Contact
+				args := gonnectrpc.ContactQuery{Device: *contactsSearchDevice, Query: strings.Join(contactsSearchCmd.Args(), " ")}
+				err = client.Call("GonnectRpc.SearchContacts", args, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				for _, c := range reply {
+					fmt.Println(c.Name)
+					for _, phone := range c.Phones {
+						fmt.Printf("  %s\n", phone)
+					}
+					for _, email := range c.Emails {
+						fmt.Printf("  %s\n", email)
+					}
+				}
+				return
+			}
+
+			fmt.Println("Usage: contacts search --device <id> <query>")
+			contactsSearchCmd.PrintDefaults()
+			os.Exit(1)
+		case "export":
+			contactsExportCmd.Parse(os.Args[3:])
+			if *contactsExportDevice != "" {
+				var reply string
+				err = client.Call("GonnectRpc.ExportContacts", *contactsExportDevice, &reply)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				if *contactsExportOutput == "" {
+					fmt.Print(reply)
+					return
+				}
+				err = os.WriteFile(*contactsExportOutput, []byte(reply), 0600)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				return
+			}
+
+			fmt.Println("Usage:")
+			contactsExportCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		fmt.Println("Usage: contacts <search|export>")
+		os.Exit(1)
+	case "lock":
+		deviceCmd.Parse(os.Args[2:])
+		if *device != "" {
+			var reply string
+			err = client.Call("GonnectRpc.LockDevice", *device, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(reply)
+			return
+		}
+
+		fmt.Println("Usage:")
+		deviceCmd.PrintDefaults()
+		os.Exit(1)
+	case "locked":
+		deviceCmd.Parse(os.Args[2:])
+		if *device != "" {
+			var reply plugins.LockStatus
+			err = client.Call("GonnectRpc.GetLockStatus", *device, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			state := "unlocked"
+			if reply.Locked {
+				state = "locked"
+			}
+			fmt.Printf("%s, updated %s\n", state, reply.Updated.Format(time.DateTime))
+			return
+		}
+
+		fmt.Println("Usage:")
+		deviceCmd.PrintDefaults()
+		os.Exit(1)
+	case "mute-ringer":
+		deviceCmd.Parse(os.Args[2:])
+		if *device != "" {
+			var reply string
+			err = client.Call("GonnectRpc.MuteRinger", *device, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(reply)
+			return
+		}
+
+		fmt.Println("Usage:")
+		deviceCmd.PrintDefaults()
+		os.Exit(1)
+	case "ping":
+		pingCmd.Parse(os.Args[2:])
+		if *pingDevice == "" {
+			fmt.Println("Usage:")
+			pingCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		if *pingLatency <= 0 {
+			var reply string
+			err = client.Call("GonnectRpc.Ping", gonnectrpc.PingArgs{Device: *pingDevice, Message: *pingMessage}, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(reply)
+			return
+		}
+
+		failed := 0
+		var total, best, worst time.Duration
+		for i := 0; i < *pingLatency; i++ {
+			if i > 0 {
+				time.Sleep(time.Second)
+			}
+
+			var latency plugins.Latency
+			err = client.Call("GonnectRpc.MeasureLatency", *pingDevice, &latency)
+			if err != nil {
+				fmt.Printf("ping %d: %s\n", i+1, err)
+				failed++
+				continue
+			}
+
+			fmt.Printf("ping %d: acknowledged after %s (smoothed rtt %s ± %s, %d retransmits)\n", i+1,
+				latency.Acked.Round(time.Microsecond),
+				latency.Rtt, latency.RttVar, latency.Retransmits)
+
+			total += latency.Acked
+			if best == 0 || latency.Acked < best {
+				best = latency.Acked
+			}
+			worst = max(worst, latency.Acked)
+		}
+
+		received := *pingLatency - failed
+		fmt.Printf("%d sent, %d acknowledged", *pingLatency, received)
+		if received > 0 {
+			fmt.Printf(", min/avg/max %s/%s/%s",
+				best.Round(time.Microsecond),
+				(total / time.Duration(received)).Round(time.Microsecond),
+				worst.Round(time.Microsecond))
+		}
+		fmt.Println()
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	case "type":
+		typeCmd.Parse(os.Args[2:])
+		if *typeDevice == "" {
+			fmt.Println("Usage: type --device <id> [--key <key>] [text|-]")
+			typeCmd.PrintDefaults()
+			os.Exit(1)
+		}
+
+		var total plugins.KeyboardStatus
+		typeOn := func(args gonnectrpc.TypeArgs) {
+			args.Device = *typeDevice
+			var reply plugins.KeyboardStatus
+			err := client.Call("GonnectRpc.Type", args, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			total.Sent += reply.Sent
+			total.Acked += reply.Acked
+			total.Known, total.Active = reply.Known, reply.Active
+		}
+
+		text := strings.Join(typeCmd.Args(), " ")
+		switch {
+		case *typeKey != "":
+			typeOn(gonnectrpc.TypeArgs{Key: *typeKey})
+		case text == "" || text == "-":
+			// Send every line as it is read so that input can be streamed
+			r := bufio.NewReader(os.Stdin)
+			for {
+				line, err := r.ReadString('\n')
+				if line != "" {
+					typeOn(gonnectrpc.TypeArgs{Text: line})
+				}
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+			}
+		default:
+			typeOn(gonnectrpc.TypeArgs{Text: text})
+		}
+
+		state := "unknown"
+		if total.Known && total.Active {
+			state = "active"
+		} else if total.Known {
+			state = "inactive"
+		}
+		fmt.Printf("%d keys sent, %d acknowledged, keyboard %s\n", total.Sent, total.Acked, state)
+		if total.Acked < total.Sent {
+			fmt.Println("select the KDE Connect remote keyboard on the device to type on it")
+			os.Exit(1)
+		}
+		return
+	case "ring":
+		deviceCmd.Parse(os.Args[2:])
+		if *device != "" {
+			var reply string
+			err = client.Call("GonnectRpc.Ring", *device, &reply)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Println(reply)
+			return
+		}
+
+		fmt.Println("Usage:")
+		deviceCmd.PrintDefaults()
+		os.Exit(1)
+	case "stop-ringing":
+		var reply string
+		err = client.Call("GonnectRpc.StopRinging", struct{}{}, &reply)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Println(reply)
+		return
+	case "events":
+		eventsCmd.Parse(os.Args[2:])
+		var lastId int64
+		for {
+			var reply []events.Event
+			err = client.Call("GonnectRpc.GetEvents", lastId, &reply)
+			if err != nil {
+				panic(err)
+			}
+
+			for _, e := range reply {
+				fmt.Printf("%s %s [%s] %s\n", e.Time.Format(time.DateTime), e.Device, e.Type, e.Message)
+				lastId = e.Id
+			}
+
+			if !*eventsFollow {
+				return
+			}
+			time.Sleep(time.Second)
+		}
 	}
 }
@@ -6,11 +6,14 @@ require github.com/grandcat/zeroconf v1.0.0 // direct
 
 require github.com/google/uuid v1.6.0 // direct
 
+require github.com/godbus/dbus/v5 v5.1.0 // direct
+
+require golang.org/x/sys v0.16.0 // direct
+
 require (
 	github.com/cenkalti/backoff v2.2.1+incompatible // indirect
 	github.com/miekg/dns v1.1.58 // indirect
 	golang.org/x/mod v0.14.0 // indirect
 	golang.org/x/net v0.20.0 // indirect
-	golang.org/x/sys v0.16.0 // indirect
 	golang.org/x/tools v0.17.0 // indirect
 )
@@ -0,0 +1,85 @@
+// Package audio lists and controls the audio outputs of the desktop through
+// one of several backends, chosen with the audioBackend setting
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+
+	"github.com/blennster/gonnect/internal/config"
+)
+
+// An audio output
+type Sink struct {
+	// Identifies the sink to the backend
+	Name string
+	// The name shown to the user
+	Description string
+	// In percent
+	Volume int
+	Muted  bool
+	// Set for the sink used by default
+	Default bool
+}
+
+type Backend interface {
+	Sinks() ([]Sink, error)
+	SetVolume(name string, volume int) error
+	SetMuted(name string, muted bool) error
+	// Make a sink the default output
+	SetDefault(name string) error
+	// Get notified when sinks change until ctx is done. Changes may be reported
+	// even if nothing visible changed
+	Watch(ctx context.Context) (<-chan struct{}, error)
+}
+
+var backends = struct {
+	m map[string]Backend
+	sync.Mutex
+}{m: make(map[string]Backend)}
+
+// Get the backend from the settings
+func Get() (Backend, error) {
+	name := config.GetSettings().AudioBackend
+	if name == "" {
+		name = detect()
+		if name == "" {
+			return nil, errors.New("no audio backend available, install pactl or wpctl")
+		}
+	}
+
+	backends.Lock()
+	defer backends.Unlock()
+
+	if b, ok := backends.m[name]; ok {
+		return b, nil
+	}
+
+	var b Backend
+	switch name {
+	case "pactl":
+		b = pactl{}
+	case "wpctl":
+		b = wpctl{}
+	case "fake":
+		b = NewFake()
+	default:
+		return nil, fmt.Errorf("unknown audio backend %q", name)
+	}
+
+	backends.m[name] = b
+	return b, nil
+}
+
+// pactl works with both pulseaudio and pipewire, so it is preferred
+func detect() string {
+	for _, name := range []string{"pactl", "wpctl"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
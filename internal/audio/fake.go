@@ -0,0 +1,84 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// A backend with made up sinks that only exist in memory
+type Fake struct {
+	sinks    []Sink
+	watchers []chan struct{}
+	sync.Mutex
+}
+
+func NewFake() *Fake {
+	return &Fake{sinks: []Sink{
+		{Name: "speakers", Description: "Speakers", Volume: 50, Default: true},
+		{Name: "headphones", Description: "Headphones", Volume: 30},
+	}}
+}
+
+func (f *Fake) Sinks() ([]Sink, error) {
+	f.Lock()
+	defer f.Unlock()
+	return append([]Sink(nil), f.sinks...), nil
+}
+
+// Change the sinks while holding the lock and tell the watchers
+func (f *Fake) update(name string, change func(sinks []Sink, i int)) error {
+	f.Lock()
+	defer f.Unlock()
+
+	i := slices.IndexFunc(f.sinks, func(s Sink) bool { return s.Name == name })
+	if i < 0 {
+		return fmt.Errorf("no sink named %q", name)
+	}
+	change(f.sinks, i)
+
+	for _, w := range f.watchers {
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (f *Fake) SetVolume(name string, volume int) error {
+	return f.update(name, func(sinks []Sink, i int) { sinks[i].Volume = volume })
+}
+
+func (f *Fake) SetMuted(name string, muted bool) error {
+	return f.update(name, func(sinks []Sink, i int) { sinks[i].Muted = muted })
+}
+
+func (f *Fake) SetDefault(name string) error {
+	return f.update(name, func(sinks []Sink, i int) {
+		for j := range sinks {
+			sinks[j].Default = j == i
+		}
+	})
+}
+
+func (f *Fake) Watch(ctx context.Context) (<-chan struct{}, error) {
+	ch := make(chan struct{}, 1)
+
+	f.Lock()
+	f.watchers = append(f.watchers, ch)
+	f.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		f.Lock()
+		defer f.Unlock()
+		for i, w := range f.watchers {
+			if w == ch {
+				f.watchers = append(f.watchers[:i], f.watchers[i+1:]...)
+				break
+			}
+		}
+	})
+	return ch, nil
+}
@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Uses pactl, which talks to pulseaudio or to pipewire through pipewire-pulse
+type pactl struct{}
+
+func (pactl) output(args ...string) ([]byte, error) {
+	out, err := exec.Command("pactl", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("pactl failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func (p pactl) Sinks() ([]Sink, error) {
+	out, err := p.output("--format=json", "list", "sinks")
+	if err != nil {
+		return nil, err
+	}
+
+	var listed []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Mute        bool   `json:"mute"`
+		Volume      map[string]struct {
+			ValuePercent string `json:"value_percent"`
+		} `json:"volume"`
+	}
+	err = json.Unmarshal(out, &listed)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSink, err := p.output("get-default-sink")
+	if err != nil {
+		return nil, err
+	}
+
+	sinks := make([]Sink, 0, len(listed))
+	for _, l := range listed {
+		// Channels may differ, so use the loudest
+		volume := 0
+		for _, channel := range l.Volume {
+			v, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(channel.ValuePercent), "%"))
+			if err == nil {
+				volume = max(volume, v)
+			}
+		}
+
+		sinks = append(sinks, Sink{
+			Name:        l.Name,
+			Description: l.Description,
+			Volume:      volume,
+			Muted:       l.Mute,
+			Default:     l.Name == strings.TrimSpace(string(defaultSink)),
+		})
+	}
+	return sinks, nil
+}
+
+func (p pactl) SetVolume(name string, volume int) error {
+	_, err := p.output("set-sink-volume", name, fmt.Sprintf("%d%%", volume))
+	return err
+}
+
+func (p pactl) SetMuted(name string, muted bool) error {
+	value := "0"
+	if muted {
+		value = "1"
+	}
+	_, err := p.output("set-sink-mute", name, value)
+	return err
+}
+
+func (p pactl) SetDefault(name string) error {
+	_, err := p.output("set-default-sink", name)
+	return err
+}
+
+// pactl subscribe prints a line for every event, such as
+// Event 'change' on sink #54
+func (pactl) Watch(ctx context.Context) (<-chan struct{}, error) {
+	cmd := exec.CommandContext(ctx, "pactl", "subscribe")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		defer close(ch)
+		defer cmd.Wait()
+
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			line := scanner.Text()
+			// The default sink changing is a server event
+			if !strings.Contains(line, " sink #") && !strings.Contains(line, " server") {
+				continue
+			}
+
+			// Changes come in bursts, only one needs to be waiting
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Uses wpctl from wireplumber, sinks are named by their pipewire id
+type wpctl struct{}
+
+func (wpctl) output(args ...string) ([]byte, error) {
+	out, err := exec.Command("wpctl", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("wpctl failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// A sink line of wpctl status, such as
+// │  *   52. Built-in Audio Analog Stereo        [vol: 0.40 MUTED]
+var wpctlSinkLine = regexp.MustCompile(`^[\s│├└─]*(\*)?\s*(\d+)\.\s+(.*?)\s+\[vol:\s*([\d.]+)(\s+MUTED)?\]`)
+
+func (w wpctl) Sinks() ([]Sink, error) {
+	out, err := w.output("status")
+	if err != nil {
+		return nil, err
+	}
+	return parseWpctlStatus(string(out)), nil
+}
+
+func parseWpctlStatus(status string) []Sink {
+	sinks := make([]Sink, 0)
+	section := ""
+	inSinks := false
+	for _, line := range strings.Split(status, "\n") {
+		trimmed := strings.Trim(line, " │├└─")
+		switch {
+		case line != "" && !strings.ContainsAny(line[:1], " │├└"):
+			// Top level sections such as Audio and Video
+			section = strings.TrimSpace(line)
+			inSinks = false
+			continue
+		case strings.HasSuffix(trimmed, ":"):
+			inSinks = section == "Audio" && trimmed == "Sinks:"
+			continue
+		}
+		if !inSinks {
+			continue
+		}
+
+		m := wpctlSinkLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		volume, _ := strconv.ParseFloat(m[4], 64)
+		sinks = append(sinks, Sink{
+			Name:        m[2],
+			Description: m[3],
+			Volume:      int(volume*100 + 0.5),
+			Muted:       m[5] != "",
+			Default:     m[1] != "",
+		})
+	}
+	return sinks
+}
+
+func (w wpctl) SetVolume(name string, volume int) error {
+	_, err := w.output("set-volume", name, fmt.Sprintf("%d%%", volume))
+	return err
+}
+
+func (w wpctl) SetMuted(name string, muted bool) error {
+	value := "0"
+	if muted {
+		value = "1"
+	}
+	_, err := w.output("set-mute", name, value)
+	return err
+}
+
+func (w wpctl) SetDefault(name string) error {
+	_, err := w.output("set-default", name)
+	return err
+}
+
+// wpctl can not subscribe to changes, so the sinks are polled
+func (w wpctl) Watch(ctx context.Context) (<-chan struct{}, error) {
+	last, err := w.Sinks()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			sinks, err := w.Sinks()
+			if err != nil || slices.Equal(sinks, last) {
+				continue
+			}
+			last = sinks
+
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return ch, nil
+}
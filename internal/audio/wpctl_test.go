@@ -0,0 +1,50 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWpctlStatus(t *testing.T) {
+	status := `PipeWire 'pipewire-0' [1.0.5, me@desk, cookie:1234]
+ └─ Clients:
+        33. WirePlumber                         [1.0.5, me@desk, pid:1000]
+
+Audio
+ ├─ Devices:
+ │      48. Built-in Audio                      [alsa]
+ │
+ ├─ Sinks:
+ │  *   52. Built-in Audio Analog Stereo        [vol: 0.40]
+ │      61. USB Headset                         [vol: 1.25 MUTED]
+ │
+ ├─ Sources:
+ │  *   53. Built-in Audio Analog Stereo        [vol: 1.00]
+ │
+ └─ Streams:
+
+Video
+ ├─ Devices:
+ │
+ ├─ Sinks:
+ │      70. Not an audio sink                   [vol: 1.00]
+ │
+ └─ Streams:
+`
+
+	want := []Sink{
+		{Name: "52", Description: "Built-in Audio Analog Stereo", Volume: 40, Default: true},
+		{Name: "61", Description: "USB Headset", Volume: 125, Muted: true},
+	}
+	sinks := parseWpctlStatus(status)
+	if !reflect.DeepEqual(sinks, want) {
+		t.Errorf("got %+v, want %+v", sinks, want)
+	}
+}
+
+func TestParseWpctlStatusEmpty(t *testing.T) {
+	sinks := parseWpctlStatus("")
+	if sinks == nil || len(sinks) != 0 {
+		t.Errorf("got %#v, want an empty list", sinks)
+	}
+}
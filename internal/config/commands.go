@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// A command the other device can ask this computer to run
+type Command struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+// Commands are kept separate from the settings since they are edited through the cli
+var commandsLock sync.Mutex
+
+func commandsPath() string {
+	return ConfigHome() + "/commands.json"
+}
+
+// Get the configured commands keyed by their id
+func GetCommands() (map[string]Command, error) {
+	commandsLock.Lock()
+	defer commandsLock.Unlock()
+
+	return readCommands()
+}
+
+func readCommands() (map[string]Command, error) {
+	commands := make(map[string]Command)
+
+	b, err := os.ReadFile(commandsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return commands, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &commands)
+	return commands, err
+}
+
+// Change the configured commands while holding the lock
+func UpdateCommands(update func(map[string]Command) error) error {
+	commandsLock.Lock()
+	defer commandsLock.Unlock()
+
+	commands, err := readCommands()
+	if err != nil {
+		return err
+	}
+
+	err = update(commands)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(commands, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(ConfigHome(), 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(commandsPath(), b, 0600)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"crypto/tls"
 	"os"
+	"path/filepath"
 
 	"github.com/google/uuid"
 )
@@ -67,10 +68,19 @@ func GetName() string {
 	return hostname
 }
 
+// Get the sysfs directory of the first battery, empty if there is none
+func BatteryPath() string {
+	batteries, _ := filepath.Glob("/sys/class/power_supply/BAT*")
+	if len(batteries) == 0 {
+		return ""
+	}
+
+	return batteries[0]
+}
+
 func GetType() string {
 	// Simple battery check
-	_, err := os.Stat("/sys/class/power_supply/BAT0")
-	if err == nil {
+	if BatteryPath() != "" {
 		return "laptop"
 	}
 
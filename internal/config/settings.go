@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// User editable settings, read from config.json in ConfigHome.
+// Every field is optional and falls back to a sane default when empty
+type Settings struct {
+	// Where files shared from other devices are saved
+	DownloadDir string `json:"downloadDir"`
+	// Command used to open shared urls, the url is passed as the last argument
+	UrlOpener string `json:"urlOpener"`
+	// What to do with shared text, either "clipboard" or "file"
+	SharedText string `json:"sharedText"`
+	// Forward desktop notifications to connected devices
+	MirrorNotifications bool `json:"mirrorNotifications"`
+	// Only forward notifications from these apps, all apps are forwarded if empty
+	MirrorAllow []string `json:"mirrorAllow"`
+	// Never forward notifications from these apps
+	MirrorDeny []string `json:"mirrorDeny"`
+	// How long a command run from another device may take, in seconds
+	CommandTimeout int `json:"commandTimeout"`
+	// Sound played when another device is looking for this computer
+	RingSound string `json:"ringSound"`
+	// Command used to play the ring sound, the sound is passed as the last argument
+	RingPlayer string `json:"ringPlayer"`
+	// Pause playing media players during calls and resume them afterwards
+	PauseMediaDuringCalls bool `json:"pauseMediaDuringCalls"`
+	// How remote input is injected, one of uinput, ydotool, xdotool, wtype or
+	// recorder. Picked from what is available when empty
+	InputBackend string `json:"inputBackend"`
+	// How audio outputs are controlled, one of pactl, wpctl or fake. Picked from
+	// what is available when empty
+	AudioBackend string `json:"audioBackend"`
+	// Command used to lock the session instead of logind
+	LockCommand string `json:"lockCommand"`
+	// Lock the session when this device goes away, off when empty
+	ProximityDevice string `json:"proximityDevice"`
+	// How long the device may stop answering before it is considered gone, in seconds
+	ProximityGrace int `json:"proximityGrace"`
+	// Show a notification when the device comes back after the session was locked
+	ProximityNotify bool `json:"proximityNotify"`
+}
+
+func ConfigHome() string {
+	if customHome, ok := os.LookupEnv("XDG_CONFIG_HOME"); ok {
+		return customHome + "/gonnect"
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		panic(err)
+	}
+
+	return homeDir + "/.config/gonnect"
+}
+
+func CacheHome() string {
+	if customHome, ok := os.LookupEnv("XDG_CACHE_HOME"); ok {
+		return customHome + "/gonnect"
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		panic(err)
+	}
+
+	return homeDir + "/.cache/gonnect"
+}
+
+// Read the settings file, it is read every time so that changes are picked up
+// without restarting the server
+func GetSettings() Settings {
+	var settings Settings
+
+	b, err := os.ReadFile(ConfigHome() + "/config.json")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read settings", "error", err)
+		}
+		return settings
+	}
+
+	err = json.Unmarshal(b, &settings)
+	if err != nil {
+		slog.Warn("failed to parse settings, using defaults", "error", err)
+		return Settings{}
+	}
+
+	return settings
+}
+
+func (s Settings) GetUrlOpener() []string {
+	opener := strings.Fields(s.UrlOpener)
+	if len(opener) == 0 {
+		return []string{"xdg-open"}
+	}
+	return opener
+}
+
+func (s Settings) GetCommandTimeout() time.Duration {
+	if s.CommandTimeout <= 0 {
+		return time.Minute
+	}
+	return time.Duration(s.CommandTimeout) * time.Second
+}
+
+func (s Settings) GetRingSound() string {
+	if s.RingSound == "" {
+		return "/usr/share/sounds/freedesktop/stereo/phone-incoming-call.oga"
+	}
+	return s.RingSound
+}
+
+func (s Settings) GetRingPlayer() []string {
+	player := strings.Fields(s.RingPlayer)
+	if len(player) == 0 {
+		return []string{"paplay"}
+	}
+	return player
+}
+
+// The command to lock the session with, nil if logind should be used
+func (s Settings) GetLockCommand() []string {
+	return strings.Fields(s.LockCommand)
+}
+
+func (s Settings) GetProximityGrace() time.Duration {
+	if s.ProximityGrace <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(s.ProximityGrace) * time.Second
+}
+
+// Check the allow and deny lists for an app, the names are case insensitive
+func (s Settings) ShouldMirror(app string) bool {
+	for _, denied := range s.MirrorDeny {
+		if strings.EqualFold(denied, app) {
+			return false
+		}
+	}
+
+	if len(s.MirrorAllow) == 0 {
+		return true
+	}
+	for _, allowed := range s.MirrorAllow {
+		if strings.EqualFold(allowed, app) {
+			return true
+		}
+	}
+	return false
+}
+
+// The directory used for received files, it is created if it does not exist
+func DownloadDir() string {
+	dir := GetSettings().DownloadDir
+	if dir == "" {
+		if xdgDir, ok := os.LookupEnv("XDG_DOWNLOAD_DIR"); ok {
+			dir = xdgDir
+		} else {
+			dir = DataHome() + "/downloads"
+		}
+	}
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		slog.Error("failed to create download directory", "dir", dir, "error", err)
+	}
+
+	return dir
+}
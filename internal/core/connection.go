@@ -1,6 +1,8 @@
 package core
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -69,21 +71,42 @@ func pair(ctx context.Context, conn *tls.Conn, identity internal.GonnectIdentity
 	}
 }
 
+// The largest packet read from a device, the connection is dropped for larger ones
+const maxPacketSize = 4 * 1024 * 1024
+
 func Handle(ctx context.Context, s *tls.Conn, identity internal.GonnectIdentity) {
+	// Everything started for this connection should stop when it is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	// Read from a connection in another goroutine to be able to sync everything
-	// The buffer should be handled with care as it is not thread safe, but can be
-	// handled by calling the function after buffer processing is done
+	// Read from a connection in another goroutine to be able to sync everything.
+	// Packets are separated by newlines and may be larger than a single read,
+	// but not larger than maxPacketSize so that a device can not use up all memory
 	recv := make(chan internal.ChanMsg)
 	go func() {
-		buf := [1024 * 4]byte{}
-		for {
-			n, err := s.Read(buf[:])
-			recv <- internal.NewChanMsg(buf[:n], err)
-			if err != nil {
+		scanner := bufio.NewScanner(s)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxPacketSize)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			select {
+			// The scanner reuses its buffer for the next line
+			case recv <- internal.ChanMsg{Msg: bytes.Clone(line)}:
+			case <-ctx.Done():
 				return
 			}
 		}
+
+		err := scanner.Err()
+		if err == nil {
+			err = io.EOF
+		}
+		select {
+		case recv <- internal.ChanMsg{Err: err}:
+		case <-ctx.Done():
+		}
 	}()
 
 	savedCert := security.Devices.Get(identity.DeviceId)
@@ -99,7 +122,8 @@ func Handle(ctx context.Context, s *tls.Conn, identity internal.GonnectIdentity)
 	savedCert = security.Devices.Get(identity.DeviceId)
 	var pluginCh <-chan plugins.GonnectPluginMessage
 	if savedCert.Equal(s.ConnectionState().PeerCertificates[0]) {
-		ctx, pluginCh = plugins.WithPlugins(ctx)
+		ctx, pluginCh = plugins.WithPlugins(ctx, identity, s.NetConn())
+		defer trackPresence(s.NetConn(), identity)()
 	}
 
 	for {
@@ -119,6 +143,7 @@ func Handle(ctx context.Context, s *tls.Conn, identity internal.GonnectIdentity)
 				slog.ErrorContext(ctx, "failed to send data", "device", identity.DeviceId, "error", err)
 				return
 			}
+			msg.Written()
 		// We received a message from the client
 		case msg := <-recv:
 			err := msg.Err
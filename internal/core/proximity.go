@@ -0,0 +1,90 @@
+package core
+
+import (
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/desktop"
+	"github.com/blennster/gonnect/internal/plugins"
+)
+
+// Presence of the proximity device. A device may reconnect before its old
+// connection is noticed as gone, so the open connections are counted
+var presence = struct {
+	connections int
+	// The session was locked because the device went away
+	locked bool
+	sync.Mutex
+}{}
+
+// Track a connection from the proximity device, the returned function must be
+// called when the connection is closed. Nothing is tracked for other devices
+func trackPresence(conn net.Conn, identity internal.GonnectIdentity) func() {
+	settings := config.GetSettings()
+	if settings.ProximityDevice == "" || settings.ProximityDevice != identity.DeviceId {
+		return func() {}
+	}
+
+	// A device that went out of range will not close the connection, so
+	// keepalives are needed to notice it
+	err := setKeepAlive(conn, settings.GetProximityGrace())
+	if err != nil {
+		slog.Warn("failed to enable keepalives", "device", identity.DeviceId, "error", err)
+	}
+
+	presence.Lock()
+	presence.connections++
+	returned := presence.locked
+	presence.locked = false
+	presence.Unlock()
+
+	if returned {
+		deviceReturned(identity)
+	}
+
+	return func() {
+		presence.Lock()
+		presence.connections--
+		gone := presence.connections == 0
+		presence.Unlock()
+
+		if gone {
+			deviceLeft(identity)
+		}
+	}
+}
+
+func deviceLeft(identity internal.GonnectIdentity) {
+	slog.Info("proximity device is gone, locking", "device", identity.DeviceId)
+	err := plugins.Lock()
+	if err != nil {
+		slog.Error("failed to lock session", "error", err)
+		return
+	}
+
+	presence.Lock()
+	// The device may have come back while locking
+	presence.locked = presence.connections == 0
+	presence.Unlock()
+}
+
+// The session is never unlocked when the device comes back, a device that was
+// taken along with the owner is not enough to get into this computer
+func deviceReturned(identity internal.GonnectIdentity) {
+	slog.Info("proximity device is back", "device", identity.DeviceId)
+	if !config.GetSettings().ProximityNotify {
+		return
+	}
+
+	_, err := desktop.Notify(desktop.Notification{
+		AppName: "gonnect",
+		Title:   identity.DeviceName,
+		Body:    "Back in range, the session was locked while it was away",
+	})
+	if err != nil {
+		slog.Warn("failed to notify about proximity device", "error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Probe the connection when it is idle and close it when the probes or sent
+// data go unanswered for the grace period
+func setKeepAlive(conn net.Conn, grace time.Duration) error {
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok {
+		return errors.New("connection is not tcp")
+	}
+
+	// Idle time plus two unanswered probes adds up to the grace period
+	interval := max(grace/3, time.Second)
+	err := tcp.SetKeepAlive(true)
+	if err != nil {
+		return err
+	}
+	err = tcp.SetKeepAlivePeriod(interval)
+	if err != nil {
+		return err
+	}
+
+	raw, err := tcp.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPCNT, 2)
+		if sockErr != nil {
+			return
+		}
+		sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_USER_TIMEOUT, int(grace.Milliseconds()))
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package core
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// The probe count and user timeout can only be set on linux, without them a
+// device that went out of range is not noticed within the grace period
+func setKeepAlive(conn net.Conn, grace time.Duration) error {
+	return errors.New("keepalive timeouts are only supported on linux")
+}
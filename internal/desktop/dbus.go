@@ -0,0 +1,51 @@
+package desktop
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Receive the signals matching options until ctx is done. The connection
+// delivers every signal to every listener, so the signals still need to be
+// filtered by the receiver
+func watchSignals(ctx context.Context, conn *dbus.Conn, options ...dbus.MatchOption) (<-chan *dbus.Signal, error) {
+	err := conn.AddMatchSignalContext(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *dbus.Signal, 10)
+	conn.Signal(ch)
+
+	out := make(chan *dbus.Signal)
+	go func() {
+		defer close(out)
+		defer func() {
+			conn.RemoveSignal(ch)
+			err := conn.RemoveMatchSignal(options...)
+			if err != nil {
+				slog.Debug("failed to remove dbus match", "error", err)
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- sig:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
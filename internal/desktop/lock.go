@@ -0,0 +1,141 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	login1Name         = "org.freedesktop.login1"
+	login1Path         = dbus.ObjectPath("/org/freedesktop/login1")
+	login1ManagerIface = "org.freedesktop.login1.Manager"
+	login1SessionIface = "org.freedesktop.login1.Session"
+	login1UserIface    = "org.freedesktop.login1.User"
+)
+
+// Find the login session of the user. The session from the environment is used
+// when started inside one, otherwise the graphical session of the user, such as
+// when running as a user service
+func session(conn *dbus.Conn) (dbus.BusObject, error) {
+	manager := conn.Object(login1Name, login1Path)
+
+	var path dbus.ObjectPath
+	if id, ok := os.LookupEnv("XDG_SESSION_ID"); ok {
+		err := manager.Call(login1ManagerIface+".GetSession", 0, id).Store(&path)
+		if err == nil {
+			return conn.Object(login1Name, path), nil
+		}
+	}
+
+	var user dbus.ObjectPath
+	err := manager.Call(login1ManagerIface+".GetUser", 0, uint32(os.Getuid())).Store(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	// The display session is a struct of the session id and its path
+	display, err := conn.Object(login1Name, user).GetProperty(login1UserIface + ".Display")
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := display.Value().([]any); ok && len(v) == 2 {
+		path, _ = v[1].(dbus.ObjectPath)
+	}
+	if path == "" || path == "/" {
+		return nil, errors.New("no graphical session found")
+	}
+
+	return conn.Object(login1Name, path), nil
+}
+
+// Lock the session of the user through logind, the screen locker of the
+// desktop does the actual locking
+func LockSession() error {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return err
+	}
+	s, err := session(conn)
+	if err != nil {
+		return err
+	}
+	return s.Call(login1SessionIface+".Lock", 0).Err
+}
+
+// Check if the session is locked, as reported by the screen locker to logind
+func SessionLocked() (bool, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return false, err
+	}
+	s, err := session(conn)
+	if err != nil {
+		return false, err
+	}
+
+	hint, err := s.GetProperty(login1SessionIface + ".LockedHint")
+	if err != nil {
+		return false, err
+	}
+	locked, _ := hint.Value().(bool)
+	return locked, nil
+}
+
+// Get notified when the session is locked or unlocked until ctx is done
+func WatchLocked(ctx context.Context) (<-chan bool, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	s, err := session(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	signals, err := watchSignals(ctx, conn,
+		dbus.WithMatchObjectPath(s.Path()),
+		dbus.WithMatchInterface(propertiesIface),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchArg(0, login1SessionIface),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan bool)
+	go func() {
+		defer close(ch)
+		for sig := range signals {
+			if sig.Name != propertiesIface+".PropertiesChanged" || sig.Path != s.Path() || len(sig.Body) < 3 {
+				continue
+			}
+
+			var locked bool
+			changed, _ := sig.Body[1].(map[string]dbus.Variant)
+			invalidated, _ := sig.Body[2].([]string)
+			if hint, ok := changed["LockedHint"]; ok {
+				locked, _ = hint.Value().(bool)
+			} else if slices.Contains(invalidated, "LockedHint") {
+				var err error
+				locked, err = SessionLocked()
+				if err != nil {
+					continue
+				}
+			} else {
+				continue
+			}
+
+			select {
+			case ch <- locked:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,303 @@
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	mprisPrefix      = "org.mpris.MediaPlayer2."
+	mprisPath        = dbus.ObjectPath("/org/mpris/MediaPlayer2")
+	mprisIface       = "org.mpris.MediaPlayer2"
+	mprisPlayerIface = "org.mpris.MediaPlayer2.Player"
+	propertiesIface  = "org.freedesktop.DBus.Properties"
+)
+
+// A media player on the session bus
+type Player struct {
+	// The name shown to the user
+	Name    string
+	BusName string
+	// The unique name of the bus connection, used to match signals to players
+	owner string
+}
+
+// What a media player is playing, times are in milliseconds
+type PlayerState struct {
+	Title         string
+	Artist        string
+	Album         string
+	ArtUrl        string
+	IsPlaying     bool
+	Position      int64
+	Length        int64
+	Volume        int
+	CanPlay       bool
+	CanPause      bool
+	CanGoNext     bool
+	CanGoPrevious bool
+	CanSeek       bool
+}
+
+// Get the media players currently on the session bus
+func Players() ([]Player, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	err = conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]Player, 0)
+	for _, busName := range names {
+		if !strings.HasPrefix(busName, mprisPrefix) {
+			continue
+		}
+
+		var owner string
+		err := conn.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0, busName).Store(&owner)
+		if err != nil {
+			// It went away while listing
+			continue
+		}
+
+		name := strings.TrimPrefix(busName, mprisPrefix)
+		identity, err := conn.Object(busName, mprisPath).GetProperty(mprisIface + ".Identity")
+		if v, ok := identity.Value().(string); err == nil && ok && v != "" {
+			name = v
+		}
+
+		// Names have to be unique since they are used to pick a player
+		base := name
+		for i := 2; hasPlayer(players, name); i++ {
+			name = fmt.Sprintf("%s [%d]", base, i)
+		}
+
+		players = append(players, Player{Name: name, BusName: busName, owner: owner})
+	}
+
+	return players, nil
+}
+
+func hasPlayer(players []Player, name string) bool {
+	for _, p := range players {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Find a player by the name shown to the user
+func FindPlayer(name string) (Player, error) {
+	players, err := Players()
+	if err != nil {
+		return Player{}, err
+	}
+
+	for _, p := range players {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Player{}, fmt.Errorf("no player named %q", name)
+}
+
+func (p Player) object() (dbus.BusObject, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Object(p.BusName, mprisPath), nil
+}
+
+func (p Player) State() (PlayerState, error) {
+	var state PlayerState
+
+	obj, err := p.object()
+	if err != nil {
+		return state, err
+	}
+
+	var props map[string]dbus.Variant
+	err = obj.Call(propertiesIface+".GetAll", 0, mprisPlayerIface).Store(&props)
+	if err != nil {
+		return state, err
+	}
+
+	status, _ := props["PlaybackStatus"].Value().(string)
+	state.IsPlaying = status == "Playing"
+	state.CanPlay, _ = props["CanPlay"].Value().(bool)
+	state.CanPause, _ = props["CanPause"].Value().(bool)
+	state.CanGoNext, _ = props["CanGoNext"].Value().(bool)
+	state.CanGoPrevious, _ = props["CanGoPrevious"].Value().(bool)
+	state.CanSeek, _ = props["CanSeek"].Value().(bool)
+
+	if position, ok := props["Position"].Value().(int64); ok {
+		state.Position = position / 1000
+	}
+	if volume, ok := props["Volume"].Value().(float64); ok {
+		state.Volume = int(volume*100 + 0.5)
+	}
+
+	metadata, _ := props["Metadata"].Value().(map[string]dbus.Variant)
+	state.Title, _ = metadata["xesam:title"].Value().(string)
+	state.Album, _ = metadata["xesam:album"].Value().(string)
+	state.ArtUrl, _ = metadata["mpris:artUrl"].Value().(string)
+	if artists, ok := metadata["xesam:artist"].Value().([]string); ok {
+		state.Artist = strings.Join(artists, ", ")
+	}
+	// Players do not agree on the type of the length
+	switch length := metadata["mpris:length"].Value().(type) {
+	case int64:
+		state.Length = length / 1000
+	case uint64:
+		state.Length = int64(length / 1000)
+	}
+
+	return state, nil
+}
+
+// Run one of Play, Pause, PlayPause, Stop, Next or Previous
+func (p Player) Action(action string) error {
+	switch action {
+	case "Play", "Pause", "PlayPause", "Stop", "Next", "Previous":
+	default:
+		return fmt.Errorf("unknown player action %q", action)
+	}
+
+	obj, err := p.object()
+	if err != nil {
+		return err
+	}
+	return obj.Call(mprisPlayerIface+"."+action, 0).Err
+}
+
+// Move the position of the current track by offset milliseconds
+func (p Player) SeekBy(offset int64) error {
+	obj, err := p.object()
+	if err != nil {
+		return err
+	}
+	return obj.Call(mprisPlayerIface+".Seek", 0, offset*1000).Err
+}
+
+// Set the position of the current track in milliseconds
+func (p Player) SetPosition(position int64) error {
+	obj, err := p.object()
+	if err != nil {
+		return err
+	}
+
+	// SetPosition only applies to the current track, which is identified by its id
+	metadata, err := obj.GetProperty(mprisPlayerIface + ".Metadata")
+	if err != nil {
+		return err
+	}
+	m, _ := metadata.Value().(map[string]dbus.Variant)
+	trackId, ok := m["mpris:trackid"].Value().(dbus.ObjectPath)
+	if !ok {
+		return fmt.Errorf("player %q has no track id", p.Name)
+	}
+
+	return obj.Call(mprisPlayerIface+".SetPosition", 0, trackId, position*1000).Err
+}
+
+// Set the volume in percent
+func (p Player) SetVolume(volume int) error {
+	obj, err := p.object()
+	if err != nil {
+		return err
+	}
+	return obj.SetProperty(mprisPlayerIface+".Volume", dbus.MakeVariant(float64(volume)/100))
+}
+
+// A change to the media players
+type PlayerChange struct {
+	// Set when the state of a player changed, nil when players were added or removed
+	Player *Player
+}
+
+// Get notified when players change state or appear and disappear until ctx is done
+func WatchPlayers(ctx context.Context) (<-chan PlayerChange, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	properties, err := watchSignals(ctx, conn,
+		dbus.WithMatchObjectPath(mprisPath),
+		dbus.WithMatchInterface(propertiesIface),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchArg(0, mprisPlayerIface),
+	)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := watchSignals(ctx, conn,
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchArg0Namespace("org.mpris.MediaPlayer2"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan PlayerChange)
+	go func() {
+		defer close(ch)
+		for {
+			var change PlayerChange
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-properties:
+				if !ok {
+					return
+				}
+				if sig.Name != propertiesIface+".PropertiesChanged" || len(sig.Body) == 0 || sig.Body[0] != mprisPlayerIface {
+					continue
+				}
+
+				players, err := Players()
+				if err != nil {
+					continue
+				}
+				for i := range players {
+					if players[i].owner == sig.Sender {
+						change.Player = &players[i]
+						break
+					}
+				}
+				if change.Player == nil {
+					continue
+				}
+			case sig, ok := <-owners:
+				if !ok {
+					return
+				}
+				if sig.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(sig.Body) == 0 {
+					continue
+				}
+				if name, _ := sig.Body[0].(string); !strings.HasPrefix(name, mprisPrefix) {
+					continue
+				}
+			}
+
+			select {
+			case ch <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,245 @@
+package desktop
+
+import (
+	"context"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notificationsName  = "org.freedesktop.Notifications"
+	notificationsPath  = dbus.ObjectPath("/org/freedesktop/Notifications")
+	notificationsIface = "org.freedesktop.Notifications"
+)
+
+// A notification shown through the desktop notification service
+type Notification struct {
+	AppName string
+	Title   string
+	Body    string
+	// Path to an image, may be empty
+	Icon string
+	// The id of a notification shown earlier that should be replaced, 0 for a new one
+	ReplacesId uint32
+	// The id the notification service gave the notification, only set for
+	// watched notifications
+	Id uint32
+	// Buttons shown on the notification, the label is also used as the action key
+	Actions []string
+}
+
+// A notification button that was clicked
+type ActionInvoked struct {
+	Id     uint32
+	Action string
+}
+
+// Show a notification, the returned id can be used to replace or close it
+func Notify(n Notification) (uint32, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return 0, err
+	}
+
+	actions := make([]string, 0, len(n.Actions)*2)
+	for _, a := range n.Actions {
+		actions = append(actions, a, a)
+	}
+
+	var id uint32
+	err = conn.Object(notificationsName, notificationsPath).Call(
+		notificationsIface+".Notify", 0,
+		n.AppName,
+		n.ReplacesId,
+		n.Icon,
+		n.Title,
+		n.Body,
+		actions,
+		map[string]dbus.Variant{},
+		int32(-1), // Let the notification service decide when it expires
+	).Store(&id)
+
+	return id, err
+}
+
+func CloseNotification(id uint32) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+
+	return conn.Object(notificationsName, notificationsPath).Call(notificationsIface+".CloseNotification", 0, id).Err
+}
+
+// Get notified when a notification button is clicked until ctx is done
+func WatchActions(ctx context.Context) (<-chan ActionInvoked, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	signals, err := watchSignals(ctx, conn,
+		dbus.WithMatchObjectPath(notificationsPath),
+		dbus.WithMatchInterface(notificationsIface),
+		dbus.WithMatchMember("ActionInvoked"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan ActionInvoked)
+	go func() {
+		defer close(ch)
+		for sig := range signals {
+			if sig.Name != notificationsIface+".ActionInvoked" || len(sig.Body) != 2 {
+				continue
+			}
+			id, _ := sig.Body[0].(uint32)
+			action, _ := sig.Body[1].(string)
+			select {
+			case ch <- ActionInvoked{Id: id, Action: action}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Get notified about every notification shown on the desktop until ctx is done.
+// Notifications shown by this process are left out so that notifications from
+// other devices are not sent back to them
+func WatchNotifications(ctx context.Context) (<-chan Notification, error) {
+	self, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	selfName := self.Names()[0]
+
+	// A monitor can not do anything else, so it needs a connection of its own
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	// The replies from the notification service are needed for the ids of new notifications
+	rules := []string{
+		"type='method_call',interface='" + notificationsIface + "',member='Notify'",
+		"type='method_return',sender='" + notificationsName + "'",
+		"type='error',sender='" + notificationsName + "'",
+	}
+	err = conn.BusObject().CallWithContext(ctx, "org.freedesktop.DBus.Monitoring.BecomeMonitor", 0, rules, uint32(0)).Err
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	messages := make(chan *dbus.Message, 10)
+	conn.Eavesdrop(messages)
+	context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+
+	// A call is identified by the sender and its serial
+	type call struct {
+		sender string
+		serial uint32
+	}
+
+	ch := make(chan Notification)
+	go func() {
+		defer close(ch)
+		// Notify calls waiting for their reply
+		pending := make(map[call]Notification)
+		// The channel is closed when the connection is
+		for msg := range messages {
+			var n Notification
+			switch msg.Type {
+			case dbus.TypeMethodCall:
+				sender := headerString(msg, dbus.FieldSender)
+				if sender == selfName || len(msg.Body) < 5 {
+					continue
+				}
+
+				n.AppName, _ = msg.Body[0].(string)
+				n.ReplacesId, _ = msg.Body[1].(uint32)
+				n.Icon, _ = msg.Body[2].(string)
+				n.Title, _ = msg.Body[3].(string)
+				n.Body, _ = msg.Body[4].(string)
+				pending[call{sender, msg.Serial()}] = n
+				continue
+			case dbus.TypeMethodReply, dbus.TypeError:
+				v, ok := msg.Headers[dbus.FieldReplySerial]
+				if !ok {
+					continue
+				}
+				serial, _ := v.Value().(uint32)
+				c := call{headerString(msg, dbus.FieldDestination), serial}
+				n, ok = pending[c]
+				if !ok {
+					continue
+				}
+				delete(pending, c)
+				if msg.Type == dbus.TypeError || len(msg.Body) < 1 {
+					continue
+				}
+				n.Id, _ = msg.Body[0].(uint32)
+			default:
+				continue
+			}
+
+			select {
+			case ch <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func headerString(msg *dbus.Message, field dbus.HeaderField) string {
+	v, ok := msg.Headers[field]
+	if !ok {
+		return ""
+	}
+	s, _ := v.Value().(string)
+	return s
+}
+
+// Get the ids of notifications closed on the desktop until ctx is done
+func WatchClosed(ctx context.Context) (<-chan uint32, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	signals, err := watchSignals(ctx, conn,
+		dbus.WithMatchObjectPath(notificationsPath),
+		dbus.WithMatchInterface(notificationsIface),
+		dbus.WithMatchMember("NotificationClosed"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan uint32)
+	go func() {
+		defer close(ch)
+		for sig := range signals {
+			if sig.Name != notificationsIface+".NotificationClosed" || len(sig.Body) != 2 {
+				continue
+			}
+			id, _ := sig.Body[0].(uint32)
+			select {
+			case ch <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
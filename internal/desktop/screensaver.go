@@ -0,0 +1,31 @@
+package desktop
+
+import "github.com/godbus/dbus/v5"
+
+const (
+	screenSaverName  = "org.freedesktop.ScreenSaver"
+	screenSaverPath  = dbus.ObjectPath("/org/freedesktop/ScreenSaver")
+	screenSaverIface = "org.freedesktop.ScreenSaver"
+)
+
+// Keep the screensaver from starting, the returned cookie is used to allow it
+// again. The inhibition is also released when the session bus connection closes
+func InhibitScreenSaver(app string, reason string) (uint32, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return 0, err
+	}
+
+	var cookie uint32
+	err = conn.Object(screenSaverName, screenSaverPath).Call(screenSaverIface+".Inhibit", 0, app, reason).Store(&cookie)
+	return cookie, err
+}
+
+func UnInhibitScreenSaver(cookie uint32) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+
+	return conn.Object(screenSaverName, screenSaverPath).Call(screenSaverIface+".UnInhibit", 0, cookie).Err
+}
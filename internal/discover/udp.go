@@ -46,7 +46,7 @@ func handleUdp(baseCtx context.Context, listener *net.UDPConn) {
 		var identityPacket internal.GonnectPacket[internal.GonnectIdentity]
 		err = json.Unmarshal(buf[:n], &identityPacket)
 		if err != nil {
-			slog.Error("error while unmarshalling udp", err)
+			slog.Error("error while unmarshalling udp", "error", err)
 			continue
 		}
 
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Something that happened which the user may want to know about, such as a
+// received share. Events are kept in memory for the cli to fetch
+type Event struct {
+	// Increasing id, used by the cli to only fetch new events
+	Id      int64
+	Time    time.Time
+	Device  string
+	Type    string
+	Message string
+}
+
+// How many events to keep around
+const maxEvents = 100
+
+var log = struct {
+	events []Event
+	lastId int64
+	sync.RWMutex
+}{}
+
+func Publish(device string, eventType string, message string) {
+	log.Lock()
+	defer log.Unlock()
+
+	log.lastId++
+	log.events = append(log.events, Event{
+		Id:      log.lastId,
+		Time:    time.Now(),
+		Device:  device,
+		Type:    eventType,
+		Message: message,
+	})
+
+	if len(log.events) > maxEvents {
+		log.events = log.events[len(log.events)-maxEvents:]
+	}
+}
+
+// Get the events with an id greater than after, oldest first
+func Since(after int64) []Event {
+	log.RLock()
+	defer log.RUnlock()
+
+	events := make([]Event, 0)
+	for _, e := range log.events {
+		if e.Id > after {
+			events = append(events, e)
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,209 @@
+package input
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Run an input tool and include its output in the error
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Uses ydotool, which works on both x11 and wayland through a daemon with
+// access to uinput
+type ydotool struct{}
+
+func (ydotool) Move(dx int, dy int) error {
+	return run("ydotool", "mousemove", "-x", strconv.Itoa(dx), "-y", strconv.Itoa(dy))
+}
+
+func (ydotool) Scroll(dx int, dy int) error {
+	return run("ydotool", "mousemove", "--wheel", "-x", strconv.Itoa(dx), "-y", strconv.Itoa(dy))
+}
+
+// The low bits are the button, 0x40 presses and 0x80 releases it
+func ydotoolButton(b Button, action int) string {
+	return fmt.Sprintf("0x%02X", int(b)|action)
+}
+
+func (ydotool) Press(b Button) error {
+	return run("ydotool", "click", ydotoolButton(b, 0x40))
+}
+
+func (ydotool) Release(b Button) error {
+	return run("ydotool", "click", ydotoolButton(b, 0x80))
+}
+
+func (ydotool) Type(text string) error {
+	return run("ydotool", "type", "--", text)
+}
+
+func (ydotool) Key(k Key, mods Modifiers) error {
+	codes, err := keyCodes(k, mods)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"key"}
+	for _, code := range codes {
+		args = append(args, fmt.Sprintf("%d:1", code))
+	}
+	for i := len(codes) - 1; i >= 0; i-- {
+		args = append(args, fmt.Sprintf("%d:0", codes[i]))
+	}
+	return run("ydotool", args...)
+}
+
+// Uses xdotool, which only works on x11
+type xdotool struct{}
+
+func (xdotool) Move(dx int, dy int) error {
+	return run("xdotool", "mousemove_relative", "--", strconv.Itoa(dx), strconv.Itoa(dy))
+}
+
+func (xdotool) Scroll(dx int, dy int) error {
+	// Scrolling is done with buttons 4 to 7, one click per step
+	scroll := func(steps int, positive string, negative string) error {
+		button := positive
+		if steps < 0 {
+			button = negative
+			steps = -steps
+		}
+		if steps == 0 {
+			return nil
+		}
+		return run("xdotool", "click", "--repeat", strconv.Itoa(steps), button)
+	}
+
+	err := scroll(dy, "4", "5")
+	if err != nil {
+		return err
+	}
+	return scroll(dx, "7", "6")
+}
+
+func xdotoolButton(b Button) string {
+	switch b {
+	case ButtonRight:
+		return "3"
+	case ButtonMiddle:
+		return "2"
+	default:
+		return "1"
+	}
+}
+
+func (xdotool) Press(b Button) error {
+	return run("xdotool", "mousedown", xdotoolButton(b))
+}
+
+func (xdotool) Release(b Button) error {
+	return run("xdotool", "mouseup", xdotoolButton(b))
+}
+
+func (xdotool) Type(text string) error {
+	return run("xdotool", "type", "--", text)
+}
+
+func (xdotool) Key(k Key, mods Modifiers) error {
+	name, err := keyName(k)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, 5)
+	if mods.Ctrl {
+		keys = append(keys, "ctrl")
+	}
+	if mods.Alt {
+		keys = append(keys, "alt")
+	}
+	if mods.Super {
+		keys = append(keys, "super")
+	}
+	if mods.Shift {
+		keys = append(keys, "shift")
+	}
+	keys = append(keys, name)
+	return run("xdotool", "key", "--", strings.Join(keys, "+"))
+}
+
+// Uses wtype, which only works on wayland and can not control the pointer
+type wtype struct{}
+
+func (wtype) Move(dx int, dy int) error {
+	return ErrUnsupported
+}
+
+func (wtype) Scroll(dx int, dy int) error {
+	return ErrUnsupported
+}
+
+func (wtype) Press(b Button) error {
+	return ErrUnsupported
+}
+
+func (wtype) Release(b Button) error {
+	return ErrUnsupported
+}
+
+func (wtype) Type(text string) error {
+	return run("wtype", "--", text)
+}
+
+func (wtype) Key(k Key, mods Modifiers) error {
+	held := make([]string, 0, 4)
+	if mods.Ctrl {
+		held = append(held, "ctrl")
+	}
+	if mods.Alt {
+		held = append(held, "alt")
+	}
+	if mods.Super {
+		held = append(held, "logo")
+	}
+	if mods.Shift {
+		held = append(held, "shift")
+	}
+
+	args := make([]string, 0)
+	for _, m := range held {
+		args = append(args, "-M", m)
+	}
+	if k.Special != KeyNone {
+		name, err := keyName(k)
+		if err != nil {
+			return err
+		}
+		args = append(args, "-k", name)
+	} else if k.Char == '-' {
+		// Would be read as an option
+		args = append(args, "-k", "minus")
+	} else {
+		args = append(args, string(k.Char))
+	}
+	for _, m := range held {
+		args = append(args, "-m", m)
+	}
+	return run("wtype", args...)
+}
+
+// The keysym name of a key, characters are used as they are
+func keyName(k Key) (string, error) {
+	if k.Special == KeyNone {
+		return string(k.Char), nil
+	}
+
+	name, ok := specialKeyNames[k.Special]
+	if !ok {
+		return "", fmt.Errorf("unknown special key %d", k.Special)
+	}
+	return name, nil
+}
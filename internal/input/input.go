@@ -0,0 +1,166 @@
+// Package input injects pointer and keyboard events into the desktop session
+// through one of several backends, chosen with the inputBackend setting
+package input
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/blennster/gonnect/internal/config"
+)
+
+type Button int
+
+const (
+	ButtonLeft Button = iota
+	ButtonRight
+	ButtonMiddle
+)
+
+// Keys that do not produce text
+type SpecialKey int
+
+const (
+	KeyNone SpecialKey = iota
+	KeyBackspace
+	KeyTab
+	KeyLinefeed
+	KeyLeft
+	KeyUp
+	KeyRight
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyDelete
+	KeyEscape
+	KeySysReq
+	KeyScrollLock
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+)
+
+// A key to press, either a special key or a character
+type Key struct {
+	Special SpecialKey
+	Char    rune
+}
+
+type Modifiers struct {
+	Shift bool
+	Ctrl  bool
+	Alt   bool
+	Super bool
+}
+
+// A way of injecting input events
+type Backend interface {
+	// Move the pointer relative to where it is
+	Move(dx int, dy int) error
+	// Scroll by a number of steps, positive dy scrolls up and positive dx right
+	Scroll(dx int, dy int) error
+	Press(b Button) error
+	Release(b Button) error
+	// Type text as if it was written on a keyboard
+	Type(text string) error
+	// Press and release a key while holding the modifiers
+	Key(k Key, mods Modifiers) error
+}
+
+var ErrUnsupported = errors.New("not supported by the input backend")
+
+var backends = struct {
+	m map[string]Backend
+	sync.Mutex
+}{m: make(map[string]Backend)}
+
+// Get the backend from the settings. Backends are created once and shared,
+// since a uinput device should not be created for every connection
+func Get() (Backend, error) {
+	name := config.GetSettings().InputBackend
+	if name == "" {
+		name = detect()
+		if name == "" {
+			return nil, errors.New("no input backend available, install ydotool, xdotool or wtype or give access to /dev/uinput")
+		}
+	}
+
+	backends.Lock()
+	defer backends.Unlock()
+
+	if b, ok := backends.m[name]; ok {
+		return b, nil
+	}
+
+	var b Backend
+	var err error
+	switch name {
+	case "uinput":
+		b, err = newUinput()
+	case "ydotool":
+		b = ydotool{}
+	case "xdotool":
+		b = xdotool{}
+	case "wtype":
+		b = wtype{}
+	case "recorder":
+		b = &Recorder{}
+	default:
+		return nil, fmt.Errorf("unknown input backend %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backends.m[name] = b
+	return b, nil
+}
+
+// Pick the first backend that looks usable in the current session
+func detect() string {
+	if f, err := os.OpenFile(uinputPath, os.O_WRONLY, 0); err == nil {
+		f.Close()
+		return "uinput"
+	}
+
+	_, wayland := os.LookupEnv("WAYLAND_DISPLAY")
+	_, x11 := os.LookupEnv("DISPLAY")
+	candidates := []string{"ydotool"}
+	if x11 && !wayland {
+		candidates = append(candidates, "xdotool")
+	}
+	if wayland {
+		candidates = append(candidates, "wtype")
+	}
+
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
+
+// Press and release a button
+func Click(b Backend, button Button) error {
+	err := b.Press(button)
+	if err != nil {
+		return err
+	}
+	return b.Release(button)
+}
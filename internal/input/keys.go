@@ -0,0 +1,176 @@
+package input
+
+import "fmt"
+
+// Linux input event codes, see linux/input-event-codes.h
+const (
+	keyEsc        = 1
+	keyBackspace  = 14
+	keyTab        = 15
+	keyEnter      = 28
+	keyLeftCtrl   = 29
+	keyLeftShift  = 42
+	keyLeftAlt    = 56
+	keySpace      = 57
+	keyF1         = 59
+	keyScrollLock = 70
+	keyF11        = 87
+	keyF12        = 88
+	keySysRq      = 99
+	keyLinefeed   = 101
+	keyHome       = 102
+	keyUp         = 103
+	keyPageUp     = 104
+	keyLeft       = 105
+	keyRight      = 106
+	keyEnd        = 107
+	keyDown       = 108
+	keyPageDown   = 109
+	keyDelete     = 111
+	keyLeftMeta   = 125
+
+	btnLeft   = 0x110
+	btnRight  = 0x111
+	btnMiddle = 0x112
+)
+
+var specialKeyCodes = map[SpecialKey]uint16{
+	KeyBackspace:  keyBackspace,
+	KeyTab:        keyTab,
+	KeyLinefeed:   keyLinefeed,
+	KeyLeft:       keyLeft,
+	KeyUp:         keyUp,
+	KeyRight:      keyRight,
+	KeyDown:       keyDown,
+	KeyPageUp:     keyPageUp,
+	KeyPageDown:   keyPageDown,
+	KeyHome:       keyHome,
+	KeyEnd:        keyEnd,
+	KeyEnter:      keyEnter,
+	KeyDelete:     keyDelete,
+	KeyEscape:     keyEsc,
+	KeySysReq:     keySysRq,
+	KeyScrollLock: keyScrollLock,
+	KeyF1:         keyF1,
+	KeyF2:         keyF1 + 1,
+	KeyF3:         keyF1 + 2,
+	KeyF4:         keyF1 + 3,
+	KeyF5:         keyF1 + 4,
+	KeyF6:         keyF1 + 5,
+	KeyF7:         keyF1 + 6,
+	KeyF8:         keyF1 + 7,
+	KeyF9:         keyF1 + 8,
+	KeyF10:        keyF1 + 9,
+	KeyF11:        keyF11,
+	KeyF12:        keyF12,
+}
+
+// X keysym names of the special keys, used by xdotool and wtype
+var specialKeyNames = map[SpecialKey]string{
+	KeyBackspace:  "BackSpace",
+	KeyTab:        "Tab",
+	KeyLinefeed:   "Linefeed",
+	KeyLeft:       "Left",
+	KeyUp:         "Up",
+	KeyRight:      "Right",
+	KeyDown:       "Down",
+	KeyPageUp:     "Prior",
+	KeyPageDown:   "Next",
+	KeyHome:       "Home",
+	KeyEnd:        "End",
+	KeyEnter:      "Return",
+	KeyDelete:     "Delete",
+	KeyEscape:     "Escape",
+	KeySysReq:     "Sys_Req",
+	KeyScrollLock: "Scroll_Lock",
+	KeyF1:         "F1",
+	KeyF2:         "F2",
+	KeyF3:         "F3",
+	KeyF4:         "F4",
+	KeyF5:         "F5",
+	KeyF6:         "F6",
+	KeyF7:         "F7",
+	KeyF8:         "F8",
+	KeyF9:         "F9",
+	KeyF10:        "F10",
+	KeyF11:        "F11",
+	KeyF12:        "F12",
+}
+
+// Rows of a us keyboard layout, without and with shift, starting at the key code
+var usLayout = []struct {
+	code    uint16
+	plain   string
+	shifted string
+}{
+	{2, "1234567890-=", "!@#$%^&*()_+"},
+	{16, "qwertyuiop[]", "QWERTYUIOP{}"},
+	{30, "asdfghjkl;'`", "ASDFGHJKL:\"~"},
+	{43, "\\zxcvbnm,./", "|ZXCVBNM<>?"},
+}
+
+// Find the key code of a character on a us keyboard layout, other layouts
+// need a backend that types text itself such as ydotool or wtype
+func charKeyCode(r rune) (code uint16, shift bool, ok bool) {
+	switch r {
+	case ' ':
+		return keySpace, false, true
+	case '\n':
+		return keyEnter, false, true
+	case '\t':
+		return keyTab, false, true
+	}
+
+	for _, row := range usLayout {
+		for i, c := range row.plain {
+			if c == r {
+				return row.code + uint16(i), false, true
+			}
+		}
+		for i, c := range row.shifted {
+			if c == r {
+				return row.code + uint16(i), true, true
+			}
+		}
+	}
+	return 0, false, false
+}
+
+// The key codes of the modifiers that are held
+func modifierCodes(mods Modifiers) []uint16 {
+	codes := make([]uint16, 0, 4)
+	if mods.Ctrl {
+		codes = append(codes, keyLeftCtrl)
+	}
+	if mods.Alt {
+		codes = append(codes, keyLeftAlt)
+	}
+	if mods.Super {
+		codes = append(codes, keyLeftMeta)
+	}
+	if mods.Shift {
+		codes = append(codes, keyLeftShift)
+	}
+	return codes
+}
+
+// The key codes to press for a key, in order, including held modifiers
+func keyCodes(k Key, mods Modifiers) ([]uint16, error) {
+	var code uint16
+	if k.Special != KeyNone {
+		var ok bool
+		code, ok = specialKeyCodes[k.Special]
+		if !ok {
+			return nil, fmt.Errorf("unknown special key %d", k.Special)
+		}
+	} else {
+		var shift, ok bool
+		code, shift, ok = charKeyCode(k.Char)
+		if !ok {
+			return nil, fmt.Errorf("no key for %q", k.Char)
+		}
+		mods.Shift = mods.Shift || shift
+	}
+
+	return append(modifierCodes(mods), code), nil
+}
@@ -0,0 +1,67 @@
+package input
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCharKeyCode(t *testing.T) {
+	tests := []struct {
+		char  rune
+		code  uint16
+		shift bool
+		ok    bool
+	}{
+		{'1', 2, false, true},
+		{'!', 2, true, true},
+		{'q', 16, false, true},
+		{'Q', 16, true, true},
+		{'a', 30, false, true},
+		{'~', 41, true, true},
+		{'\\', 43, false, true},
+		{'?', 53, true, true},
+		{' ', keySpace, false, true},
+		{'\n', keyEnter, false, true},
+		{'\t', keyTab, false, true},
+		{'é', 0, false, false},
+	}
+
+	for _, test := range tests {
+		code, shift, ok := charKeyCode(test.char)
+		if code != test.code || shift != test.shift || ok != test.ok {
+			t.Errorf("charKeyCode(%q) = %d, %v, %v, want %d, %v, %v", test.char, code, shift, ok, test.code, test.shift, test.ok)
+		}
+	}
+}
+
+func TestKeyCodes(t *testing.T) {
+	tests := []struct {
+		key   Key
+		mods  Modifiers
+		codes []uint16
+	}{
+		{Key{Char: 'a'}, Modifiers{}, []uint16{30}},
+		{Key{Char: 'A'}, Modifiers{}, []uint16{keyLeftShift, 30}},
+		{Key{Char: 'c'}, Modifiers{Ctrl: true}, []uint16{keyLeftCtrl, 46}},
+		{Key{Special: KeyEnter}, Modifiers{}, []uint16{keyEnter}},
+		{Key{Special: KeyF5}, Modifiers{Alt: true}, []uint16{keyLeftAlt, keyF1 + 4}},
+		{Key{Special: KeyF12}, Modifiers{}, []uint16{keyF12}},
+		{Key{Special: KeyLeft}, Modifiers{Ctrl: true, Super: true, Shift: true}, []uint16{keyLeftCtrl, keyLeftMeta, keyLeftShift, keyLeft}},
+	}
+
+	for _, test := range tests {
+		codes, err := keyCodes(test.key, test.mods)
+		if err != nil {
+			t.Errorf("keyCodes(%+v, %+v) failed: %s", test.key, test.mods, err)
+			continue
+		}
+		if !slices.Equal(codes, test.codes) {
+			t.Errorf("keyCodes(%+v, %+v) = %v, want %v", test.key, test.mods, codes, test.codes)
+		}
+	}
+
+	_, err := keyCodes(Key{Char: 'é'}, Modifiers{})
+	if err == nil {
+		t.Error("keyCodes of a character not on the layout did not fail")
+	}
+}
@@ -0,0 +1,61 @@
+package input
+
+import "sync"
+
+// An input event kept by the recorder, only the fields for the kind are set
+type Event struct {
+	// One of move, scroll, press, release, type or key
+	Kind      string
+	X         int
+	Y         int
+	Button    Button
+	Text      string
+	Key       Key
+	Modifiers Modifiers
+}
+
+// A backend that keeps the events in memory instead of injecting them
+type Recorder struct {
+	events []Event
+	sync.Mutex
+}
+
+func (r *Recorder) record(e Event) error {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, e)
+	return nil
+}
+
+// Get the recorded events and forget them
+func (r *Recorder) Take() []Event {
+	r.Lock()
+	defer r.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func (r *Recorder) Move(dx int, dy int) error {
+	return r.record(Event{Kind: "move", X: dx, Y: dy})
+}
+
+func (r *Recorder) Scroll(dx int, dy int) error {
+	return r.record(Event{Kind: "scroll", X: dx, Y: dy})
+}
+
+func (r *Recorder) Press(b Button) error {
+	return r.record(Event{Kind: "press", Button: b})
+}
+
+func (r *Recorder) Release(b Button) error {
+	return r.record(Event{Kind: "release", Button: b})
+}
+
+func (r *Recorder) Type(text string) error {
+	return r.record(Event{Kind: "type", Text: text})
+}
+
+func (r *Recorder) Key(k Key, mods Modifiers) error {
+	return r.record(Event{Kind: "key", Key: k, Modifiers: mods})
+}
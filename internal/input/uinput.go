@@ -0,0 +1,174 @@
+package input
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const uinputPath = "/dev/uinput"
+
+// ioctls and event types, see linux/uinput.h and linux/input-event-codes.h
+const (
+	uiDevCreate = 0x5501
+	uiDevSetup  = 0x405c5503
+	uiSetEvBit  = 0x40045564
+	uiSetKeyBit = 0x40045565
+	uiSetRelBit = 0x40045566
+
+	evSyn = 0
+	evKey = 1
+	evRel = 2
+
+	synReport = 0
+
+	relX      = 0
+	relY      = 1
+	relHWheel = 6
+	relWheel  = 8
+)
+
+// A virtual keyboard and mouse created through the uinput kernel module.
+// It works on both x11 and wayland but needs write access to /dev/uinput
+type uinput struct {
+	f *os.File
+	sync.Mutex
+}
+
+func newUinput() (*uinput, error) {
+	f, err := os.OpenFile(uinputPath, os.O_WRONLY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fd := int(f.Fd())
+	setup := func() error {
+		for _, ev := range []int{evSyn, evKey, evRel} {
+			if err := unix.IoctlSetInt(fd, uiSetEvBit, ev); err != nil {
+				return err
+			}
+		}
+		// Every keyboard key and the mouse buttons
+		for code := 1; code < 256; code++ {
+			if err := unix.IoctlSetInt(fd, uiSetKeyBit, code); err != nil {
+				return err
+			}
+		}
+		for _, code := range []int{btnLeft, btnRight, btnMiddle} {
+			if err := unix.IoctlSetInt(fd, uiSetKeyBit, code); err != nil {
+				return err
+			}
+		}
+		for _, code := range []int{relX, relY, relWheel, relHWheel} {
+			if err := unix.IoctlSetInt(fd, uiSetRelBit, code); err != nil {
+				return err
+			}
+		}
+
+		// struct uinput_setup: struct input_id, a name of 80 bytes and ff_effects_max
+		var dev [92]byte
+		binary.LittleEndian.PutUint16(dev[0:], 0x06) // BUS_VIRTUAL
+		binary.LittleEndian.PutUint16(dev[2:], 0x1209)
+		binary.LittleEndian.PutUint16(dev[4:], 0x0716)
+		copy(dev[8:87], "gonnect remote input")
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uiDevSetup, uintptr(unsafe.Pointer(&dev[0])))
+		if errno != 0 {
+			return errno
+		}
+
+		_, _, errno = unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uiDevCreate, 0)
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+
+	err = setup()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &uinput{f: f}, nil
+}
+
+// Write events followed by a sync report
+func (u *uinput) emit(events ...[3]int32) error {
+	u.Lock()
+	defer u.Unlock()
+
+	// struct input_event starts with a struct timeval, which the kernel fills in
+	timeSize := int(unsafe.Sizeof(unix.Timeval{}))
+	buf := make([]byte, 0, (len(events)+1)*(timeSize+8))
+	for _, e := range append(events, [3]int32{evSyn, synReport, 0}) {
+		buf = append(buf, make([]byte, timeSize)...)
+		buf = binary.NativeEndian.AppendUint16(buf, uint16(e[0]))
+		buf = binary.NativeEndian.AppendUint16(buf, uint16(e[1]))
+		buf = binary.NativeEndian.AppendUint32(buf, uint32(e[2]))
+	}
+
+	_, err := u.f.Write(buf)
+	return err
+}
+
+func (u *uinput) Move(dx int, dy int) error {
+	return u.emit([3]int32{evRel, relX, int32(dx)}, [3]int32{evRel, relY, int32(dy)})
+}
+
+func (u *uinput) Scroll(dx int, dy int) error {
+	return u.emit([3]int32{evRel, relHWheel, int32(dx)}, [3]int32{evRel, relWheel, int32(dy)})
+}
+
+func buttonCode(b Button) int32 {
+	switch b {
+	case ButtonRight:
+		return btnRight
+	case ButtonMiddle:
+		return btnMiddle
+	default:
+		return btnLeft
+	}
+}
+
+func (u *uinput) Press(b Button) error {
+	return u.emit([3]int32{evKey, buttonCode(b), 1})
+}
+
+func (u *uinput) Release(b Button) error {
+	return u.emit([3]int32{evKey, buttonCode(b), 0})
+}
+
+// Press the keys in order and release them in reverse
+func (u *uinput) tap(codes []uint16) error {
+	for _, code := range codes {
+		if err := u.emit([3]int32{evKey, int32(code), 1}); err != nil {
+			return err
+		}
+	}
+	for i := len(codes) - 1; i >= 0; i-- {
+		if err := u.emit([3]int32{evKey, int32(codes[i]), 0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *uinput) Type(text string) error {
+	for _, r := range text {
+		err := u.Key(Key{Char: r}, Modifiers{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *uinput) Key(k Key, mods Modifiers) error {
+	codes, err := keyCodes(k, mods)
+	if err != nil {
+		return err
+	}
+	return u.tap(codes)
+}
@@ -19,6 +19,43 @@ const (
 	GonnectClipboardType        = GonnectMessageType("kdeconnect.clipboard")
 	GonnectClipboardConnectType = GonnectMessageType("kdeconnect.clipboard.connect")
 	GonnectIdentityType         = GonnectMessageType("kdeconnect.identity")
+	GonnectShareRequestType     = GonnectMessageType("kdeconnect.share.request")
+	GonnectNotificationType     = GonnectMessageType("kdeconnect.notification")
+	GonnectBatteryType          = GonnectMessageType("kdeconnect.battery")
+	GonnectRunCommandType       = GonnectMessageType("kdeconnect.runcommand")
+	GonnectMprisType            = GonnectMessageType("kdeconnect.mpris")
+	GonnectMprisRequestType     = GonnectMessageType("kdeconnect.mpris.request")
+	GonnectFindMyPhoneType      = GonnectMessageType("kdeconnect.findmyphone.request")
+	// Text messages of the other device and the requests for them
+	GonnectSmsMessagesType             = GonnectMessageType("kdeconnect.sms.messages")
+	GonnectSmsRequestType              = GonnectMessageType("kdeconnect.sms.request")
+	GonnectSmsRequestConversationsType = GonnectMessageType("kdeconnect.sms.request_conversations")
+	GonnectSmsRequestConversationType  = GonnectMessageType("kdeconnect.sms.request_conversation")
+	// Contacts of the other device, synced by comparing the timestamps of each contact
+	GonnectContactsRequestAllType     = GonnectMessageType("kdeconnect.contacts.request_all_uids_timestamps")
+	GonnectContactsRequestVcardsType  = GonnectMessageType("kdeconnect.contacts.request_vcards_by_uid")
+	GonnectContactsResponseUidsType   = GonnectMessageType("kdeconnect.contacts.response_uids_timestamps")
+	GonnectContactsResponseVcardsType = GonnectMessageType("kdeconnect.contacts.response_vcards")
+	// Calls on the other device and muting its ringtone
+	GonnectTelephonyType            = GonnectMessageType("kdeconnect.telephony")
+	GonnectTelephonyRequestMuteType = GonnectMessageType("kdeconnect.telephony.request_mute")
+	// Remote input in both directions, and the remote keyboard of the other device
+	GonnectMousepadRequestType       = GonnectMessageType("kdeconnect.mousepad.request")
+	GonnectMousepadEchoType          = GonnectMessageType("kdeconnect.mousepad.echo")
+	GonnectMousepadKeyboardStateType = GonnectMessageType("kdeconnect.mousepad.keyboardstate")
+	GonnectPresenterType             = GonnectMessageType("kdeconnect.presenter")
+	// Audio outputs of this computer controlled from the other device
+	GonnectSystemVolumeType        = GonnectMessageType("kdeconnect.systemvolume")
+	GonnectSystemVolumeRequestType = GonnectMessageType("kdeconnect.systemvolume.request")
+	// Locking this computer or the other device
+	GonnectLockType        = GonnectMessageType("kdeconnect.lock")
+	GonnectLockRequestType = GonnectMessageType("kdeconnect.lock.request")
+	// Sent by the other device to get the commands or run one of them
+	GonnectRunCommandRequestType = GonnectMessageType("kdeconnect.runcommand.request")
+	// Packets sent to act on notifications on the other device
+	GonnectNotificationReplyType   = GonnectMessageType("kdeconnect.notification.reply")
+	GonnectNotificationActionType  = GonnectMessageType("kdeconnect.notification.action")
+	GonnectNotificationRequestType = GonnectMessageType("kdeconnect.notification.request")
 )
 
 const (
@@ -29,6 +66,15 @@ type GonnectPacket[T any] struct {
 	Id   int64              `json:"id"`
 	Type GonnectMessageType `json:"type"`
 	Body T                  `json:"body"`
+
+	// Set when the packet is followed by a payload on a separate connection
+	PayloadSize         int64                `json:"payloadSize,omitempty"`
+	PayloadTransferInfo *PayloadTransferInfo `json:"payloadTransferInfo,omitempty"`
+}
+
+// Tells the receiver where to connect to fetch the payload of a packet
+type PayloadTransferInfo struct {
+	Port int `json:"port"`
 }
 
 func Infer[T any](pkt GonnectPacket[any]) (*T, error) {
@@ -71,6 +117,312 @@ type GonnectClipboardConnect struct {
 	Timestamp int `json:"timestamp"`
 }
 
+type GonnectShareRequest struct {
+	Filename string `json:"filename,omitempty"`
+	// Timestamps are in milliseconds since epoch
+	CreationTime int64 `json:"creationTime,omitempty"`
+	LastModified int64 `json:"lastModified,omitempty"`
+	Open         bool  `json:"open,omitempty"`
+	// Set when multiple files are shared at once
+	NumberOfFiles    int   `json:"numberOfFiles,omitempty"`
+	TotalPayloadSize int64 `json:"totalPayloadSize,omitempty"`
+	// Shares without a payload carry either an url or text instead
+	Url  string `json:"url,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+type GonnectNotification struct {
+	Id      string `json:"id"`
+	AppName string `json:"appName,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Ticker  string `json:"ticker,omitempty"`
+	// Milliseconds since epoch, sent as a string
+	Time        string `json:"time,omitempty"`
+	IsClearable bool   `json:"isClearable,omitempty"`
+	// The notification was removed on the other device
+	IsCancel bool `json:"isCancel,omitempty"`
+	OnlyOnce bool `json:"onlyOnce,omitempty"`
+	// Notifications that already existed are sent silently when connecting
+	Silent bool `json:"silent,omitempty"`
+	// Identifies the icon sent as payload
+	PayloadHash string `json:"payloadHash,omitempty"`
+	// Set if the notification can be replied to
+	RequestReplyId string `json:"requestReplyId,omitempty"`
+	// Names of the buttons on the notification
+	Actions []string `json:"actions,omitempty"`
+}
+
+type GonnectBattery struct {
+	CurrentCharge int  `json:"currentCharge"`
+	IsCharging    bool `json:"isCharging"`
+	// BatteryThresholdLow when the battery is low, otherwise BatteryThresholdNone
+	ThresholdEvent int `json:"thresholdEvent"`
+}
+
+const (
+	BatteryThresholdNone = 0
+	BatteryThresholdLow  = 1
+)
+
+type GonnectRunCommand struct {
+	// The commands as a json encoded object of config.Command keyed by id
+	CommandList   string `json:"commandList"`
+	CanAddCommand bool   `json:"canAddCommand"`
+}
+
+type GonnectRunCommandRequest struct {
+	RequestCommandList bool `json:"requestCommandList,omitempty"`
+	// The id of the command to run
+	Key string `json:"key,omitempty"`
+	// Asks to open the command configuration
+	Setup bool `json:"setup,omitempty"`
+}
+
+// The media players of a device. The list is always sent, even when empty, so
+// that the other device learns when the last player is gone
+type GonnectMprisPlayerList struct {
+	PlayerList             []string `json:"playerList"`
+	SupportAlbumArtPayload bool     `json:"supportAlbumArtPayload,omitempty"`
+}
+
+// Describes the media players of a device, either the list of players or the
+// state of one of them. Times are in milliseconds
+type GonnectMpris struct {
+	PlayerList             []string `json:"playerList,omitempty"`
+	SupportAlbumArtPayload bool     `json:"supportAlbumArtPayload,omitempty"`
+
+	Player        string `json:"player,omitempty"`
+	Title         string `json:"title,omitempty"`
+	Artist        string `json:"artist,omitempty"`
+	Album         string `json:"album,omitempty"`
+	AlbumArtUrl   string `json:"albumArtUrl,omitempty"`
+	IsPlaying     bool   `json:"isPlaying"`
+	Pos           int64  `json:"pos"`
+	Length        int64  `json:"length"`
+	Volume        int    `json:"volume"`
+	CanPlay       bool   `json:"canPlay"`
+	CanPause      bool   `json:"canPause"`
+	CanGoNext     bool   `json:"canGoNext"`
+	CanGoPrevious bool   `json:"canGoPrevious"`
+	CanSeek       bool   `json:"canSeek"`
+	// Set when the album art of AlbumArtUrl is attached as payload
+	TransferringAlbumArt bool `json:"transferringAlbumArt,omitempty"`
+}
+
+// Asks for the state of the media players of a device or controls one of them
+type GonnectMprisRequest struct {
+	RequestPlayerList bool   `json:"requestPlayerList,omitempty"`
+	Player            string `json:"player,omitempty"`
+	RequestNowPlaying bool   `json:"requestNowPlaying,omitempty"`
+	RequestVolume     bool   `json:"requestVolume,omitempty"`
+	// One of Play, Pause, PlayPause, Stop, Next or Previous
+	Action    string `json:"action,omitempty"`
+	SetVolume *int   `json:"setVolume,omitempty"`
+	// Relative seek in microseconds, the capitalization is part of the protocol
+	Seek int64 `json:"Seek,omitempty"`
+	// Absolute position in milliseconds
+	SetPosition *int64 `json:"SetPosition,omitempty"`
+	// Asks for the album art at the url to be sent as payload
+	AlbumArtUrl string `json:"albumArtUrl,omitempty"`
+}
+
+// Makes the receiving device play a sound, sending it again stops it
+type GonnectFindMyPhone struct{}
+
+type GonnectSmsAddress struct {
+	Address string `json:"address"`
+}
+
+// A text message, dates are in milliseconds since the epoch
+type GonnectSmsMessage struct {
+	Id        int64               `json:"_id"`
+	ThreadId  int64               `json:"thread_id"`
+	Event     int                 `json:"event"`
+	Body      string              `json:"body"`
+	Addresses []GonnectSmsAddress `json:"addresses"`
+	Date      int64               `json:"date"`
+	// SmsInbox for received messages and SmsSent for sent ones
+	MessageType int   `json:"type"`
+	Read        int   `json:"read"`
+	SubId       int64 `json:"sub_id"`
+}
+
+const (
+	SmsInbox = 1
+	SmsSent  = 2
+)
+
+// Sent by the other device as a response to the conversation requests
+type GonnectSmsMessages struct {
+	Messages []GonnectSmsMessage `json:"messages"`
+	Version  int                 `json:"version,omitempty"`
+}
+
+// Asks for the latest message of every conversation
+type GonnectSmsRequestConversations struct{}
+
+// Asks for the messages of one conversation
+type GonnectSmsRequestConversation struct {
+	ThreadId int64 `json:"threadID"`
+}
+
+// Sends a text message from the other device
+type GonnectSmsRequest struct {
+	Version   int                 `json:"version"`
+	SendSms   bool                `json:"sendSms"`
+	Addresses []GonnectSmsAddress `json:"addresses"`
+	// Used by older versions instead of Addresses
+	PhoneNumber string `json:"phoneNumber,omitempty"`
+	MessageBody string `json:"messageBody"`
+}
+
+// Remote input, pointer motion or scrolling in dx and dy, clicks and key presses
+type GonnectMousepadRequest struct {
+	Dx            float64 `json:"dx,omitempty"`
+	Dy            float64 `json:"dy,omitempty"`
+	Scroll        bool    `json:"scroll,omitempty"`
+	SingleClick   bool    `json:"singleclick,omitempty"`
+	DoubleClick   bool    `json:"doubleclick,omitempty"`
+	MiddleClick   bool    `json:"middleclick,omitempty"`
+	RightClick    bool    `json:"rightclick,omitempty"`
+	SingleHold    bool    `json:"singlehold,omitempty"`
+	SingleRelease bool    `json:"singlerelease,omitempty"`
+	// Text to type
+	Key string `json:"key,omitempty"`
+	// One of the MousepadKey constants
+	SpecialKey int  `json:"specialKey,omitempty"`
+	Shift      bool `json:"shift,omitempty"`
+	Ctrl       bool `json:"ctrl,omitempty"`
+	Alt        bool `json:"alt,omitempty"`
+	Super      bool `json:"super,omitempty"`
+	// Asks for the request to be echoed back once handled
+	SendAck bool `json:"sendAck,omitempty"`
+}
+
+// The special keys of the mousepad protocol, 17 to 20 are unused
+const (
+	MousepadKeyBackspace  = 1
+	MousepadKeyTab        = 2
+	MousepadKeyLinefeed   = 3
+	MousepadKeyLeft       = 4
+	MousepadKeyUp         = 5
+	MousepadKeyRight      = 6
+	MousepadKeyDown       = 7
+	MousepadKeyPageUp     = 8
+	MousepadKeyPageDown   = 9
+	MousepadKeyHome       = 10
+	MousepadKeyEnd        = 11
+	MousepadKeyEnter      = 12
+	MousepadKeyDelete     = 13
+	MousepadKeyEscape     = 14
+	MousepadKeySysReq     = 15
+	MousepadKeyScrollLock = 16
+	MousepadKeyF1         = 21
+	MousepadKeyF12        = 32
+)
+
+// A handled mousepad request sent back when it asked for it
+type GonnectMousepadEcho struct {
+	GonnectMousepadRequest
+	IsAck bool `json:"isAck"`
+}
+
+// Tells if remote input is accepted, or on the phone if its keyboard is active
+type GonnectMousepadKeyboardState struct {
+	State bool `json:"state"`
+}
+
+// Pointer motion from a presentation remote, in fractions of half the screen.
+// The slide keys are sent as mousepad requests
+type GonnectPresenter struct {
+	Dx   float64 `json:"dx,omitempty"`
+	Dy   float64 `json:"dy,omitempty"`
+	Stop bool    `json:"stop,omitempty"`
+}
+
+type GonnectSystemVolumeSink struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Muted       bool   `json:"muted"`
+	Volume      int    `json:"volume"`
+	MaxVolume   int    `json:"maxVolume"`
+	// Set for the default sink
+	Enabled bool `json:"enabled"`
+}
+
+// The audio outputs of this computer
+type GonnectSystemVolume struct {
+	SinkList []GonnectSystemVolumeSink `json:"sinkList"`
+}
+
+// Asks for the audio outputs or changes the one named by Name
+type GonnectSystemVolumeRequest struct {
+	RequestSinks bool   `json:"requestSinks,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Volume       *int   `json:"volume,omitempty"`
+	Muted        *bool  `json:"muted,omitempty"`
+	// Makes the sink the default one
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// The lock state of a device
+type GonnectLock struct {
+	IsLocked bool `json:"isLocked"`
+}
+
+// Asks for the lock state of a device or locks it
+type GonnectLockRequest struct {
+	RequestLocked bool  `json:"requestLocked,omitempty"`
+	SetLocked     *bool `json:"setLocked,omitempty"`
+}
+
+// A change to the call state of the other device, sent again with IsCancel
+// set when the call is over
+type GonnectTelephony struct {
+	// One of TelephonyRinging, TelephonyTalking or TelephonyMissedCall
+	Event       string `json:"event"`
+	PhoneNumber string `json:"phoneNumber,omitempty"`
+	ContactName string `json:"contactName,omitempty"`
+	IsCancel    bool   `json:"isCancel,omitempty"`
+}
+
+const (
+	TelephonyRinging    = "ringing"
+	TelephonyTalking    = "talking"
+	TelephonyMissedCall = "missedCall"
+)
+
+// Asks the other device to mute the ringtone of an incoming call
+type GonnectTelephonyRequestMute struct{}
+
+// Asks for the uid and last modified timestamp of every contact
+type GonnectContactsRequestAll struct{}
+
+// Asks for the vcards of the contacts. The responses have the uids as keys,
+// with the timestamps or vcards as values, next to the list of uids
+type GonnectContactsRequestVcards struct {
+	Uids []string `json:"uids"`
+}
+
+type GonnectNotificationReply struct {
+	RequestReplyId string `json:"requestReplyId"`
+	Message        string `json:"message"`
+}
+
+type GonnectNotificationAction struct {
+	// The id of the notification
+	Key    string `json:"key"`
+	Action string `json:"action"`
+}
+
+type GonnectNotificationRequest struct {
+	// Ask for all current notifications
+	Request bool `json:"request,omitempty"`
+	// The id of a notification to dismiss
+	Cancel string `json:"cancel,omitempty"`
+}
+
 func (GonnectIdentity) Type() GonnectMessageType {
 	return GonnectIdentityType
 }
@@ -91,6 +443,118 @@ func (GonnectClipboardConnect) Type() GonnectMessageType {
 	return GonnectClipboardConnectType
 }
 
+func (GonnectShareRequest) Type() GonnectMessageType {
+	return GonnectShareRequestType
+}
+
+func (GonnectNotification) Type() GonnectMessageType {
+	return GonnectNotificationType
+}
+
+func (GonnectBattery) Type() GonnectMessageType {
+	return GonnectBatteryType
+}
+
+func (GonnectRunCommand) Type() GonnectMessageType {
+	return GonnectRunCommandType
+}
+
+func (GonnectRunCommandRequest) Type() GonnectMessageType {
+	return GonnectRunCommandRequestType
+}
+
+func (GonnectMprisPlayerList) Type() GonnectMessageType {
+	return GonnectMprisType
+}
+
+func (GonnectMpris) Type() GonnectMessageType {
+	return GonnectMprisType
+}
+
+func (GonnectMprisRequest) Type() GonnectMessageType {
+	return GonnectMprisRequestType
+}
+
+func (GonnectFindMyPhone) Type() GonnectMessageType {
+	return GonnectFindMyPhoneType
+}
+
+func (GonnectSmsMessages) Type() GonnectMessageType {
+	return GonnectSmsMessagesType
+}
+
+func (GonnectSmsRequestConversations) Type() GonnectMessageType {
+	return GonnectSmsRequestConversationsType
+}
+
+func (GonnectSmsRequestConversation) Type() GonnectMessageType {
+	return GonnectSmsRequestConversationType
+}
+
+func (GonnectSmsRequest) Type() GonnectMessageType {
+	return GonnectSmsRequestType
+}
+
+func (GonnectMousepadRequest) Type() GonnectMessageType {
+	return GonnectMousepadRequestType
+}
+
+func (GonnectMousepadEcho) Type() GonnectMessageType {
+	return GonnectMousepadEchoType
+}
+
+func (GonnectMousepadKeyboardState) Type() GonnectMessageType {
+	return GonnectMousepadKeyboardStateType
+}
+
+func (GonnectPresenter) Type() GonnectMessageType {
+	return GonnectPresenterType
+}
+
+func (GonnectSystemVolume) Type() GonnectMessageType {
+	return GonnectSystemVolumeType
+}
+
+func (GonnectSystemVolumeRequest) Type() GonnectMessageType {
+	return GonnectSystemVolumeRequestType
+}
+
+func (GonnectLock) Type() GonnectMessageType {
+	return GonnectLockType
+}
+
+func (GonnectLockRequest) Type() GonnectMessageType {
+	return GonnectLockRequestType
+}
+
+func (GonnectTelephony) Type() GonnectMessageType {
+	return GonnectTelephonyType
+}
+
+func (GonnectTelephonyRequestMute) Type() GonnectMessageType {
+	return GonnectTelephonyRequestMuteType
+}
+
+func (GonnectContactsRequestAll) Type() GonnectMessageType {
+	return GonnectContactsRequestAllType
+}
+
+func (GonnectContactsRequestVcards) Type() GonnectMessageType {
+	return GonnectContactsRequestVcardsType
+}
+
+func (GonnectNotificationReply) Type() GonnectMessageType {
+	return GonnectNotificationReplyType
+}
+
+func (GonnectNotificationAction) Type() GonnectMessageType {
+	return GonnectNotificationActionType
+}
+
+func (GonnectNotificationRequest) Type() GonnectMessageType {
+	return GonnectNotificationRequestType
+}
+
 func NewGonnectPacket[T GonnectPacketType](body T) GonnectPacket[T] {
 	return GonnectPacket[T]{
 		Id:   time.Now().Unix(),
@@ -114,11 +578,51 @@ func Identity() GonnectIdentity {
 		"kdeconnect.ping",
 		"kdeconnect.clipboard",
 		"kdeconnect.clipboard.connect",
+		"kdeconnect.share.request",
+		"kdeconnect.notification",
+		"kdeconnect.battery",
+		"kdeconnect.runcommand.request",
+		"kdeconnect.mpris.request",
+		"kdeconnect.mpris",
+		"kdeconnect.findmyphone.request",
+		"kdeconnect.sms.messages",
+		"kdeconnect.contacts.response_uids_timestamps",
+		"kdeconnect.contacts.response_vcards",
+		"kdeconnect.telephony",
+		"kdeconnect.mousepad.request",
+		"kdeconnect.mousepad.echo",
+		"kdeconnect.mousepad.keyboardstate",
+		"kdeconnect.presenter",
+		"kdeconnect.systemvolume.request",
+		"kdeconnect.lock",
+		"kdeconnect.lock.request",
 	}
 	identity.OutgoingCapabilities = []string{
 		"kdeconnect.ping",
 		"kdeconnect.clipboard",
 		"kdeconnect.clipboard.connect",
+		"kdeconnect.share.request",
+		"kdeconnect.notification",
+		"kdeconnect.notification.reply",
+		"kdeconnect.notification.action",
+		"kdeconnect.notification.request",
+		"kdeconnect.battery",
+		"kdeconnect.runcommand",
+		"kdeconnect.mpris",
+		"kdeconnect.mpris.request",
+		"kdeconnect.findmyphone.request",
+		"kdeconnect.sms.request",
+		"kdeconnect.sms.request_conversations",
+		"kdeconnect.sms.request_conversation",
+		"kdeconnect.contacts.request_all_uids_timestamps",
+		"kdeconnect.contacts.request_vcards_by_uid",
+		"kdeconnect.telephony.request_mute",
+		"kdeconnect.mousepad.request",
+		"kdeconnect.mousepad.echo",
+		"kdeconnect.mousepad.keyboardstate",
+		"kdeconnect.systemvolume",
+		"kdeconnect.lock",
+		"kdeconnect.lock.request",
 	}
 
 	return identity
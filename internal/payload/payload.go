@@ -0,0 +1,184 @@
+// Package payload moves the data attached to a packet over a separate tls
+// connection, as is done for shared files, notification icons and album art.
+//
+// The side that has the data listens on a port in the payload range and
+// announces it in the packet's payloadTransferInfo, the other side then
+// connects and reads exactly payloadSize bytes. Unlike the main connection
+// the side that dials is the tls client.
+package payload
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/security"
+	"golang.org/x/sys/unix"
+)
+
+// The ports the other device expects payloads to be served on
+const (
+	PortMin = 1739
+	PortMax = 1764
+)
+
+// How long to wait for the other device to connect for a payload
+const AcceptTimeout = 30 * time.Second
+
+var ErrShortTransfer = errors.New("payload ended before the announced size")
+
+// Called with the total number of bytes transferred so far
+type Progress func(transferred int64)
+
+type progressWriter struct {
+	w           io.Writer
+	progress    Progress
+	transferred int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.transferred += int64(n)
+	if p.progress != nil {
+		p.progress(p.transferred)
+	}
+	return n, err
+}
+
+// Check the announced size of a payload, a limit of 0 means that any size is fine
+func checkSize(size int64, limit int64) error {
+	if size < 0 {
+		return fmt.Errorf("invalid payload size %d", size)
+	}
+	if limit > 0 && size > limit {
+		return fmt.Errorf("payload of %d bytes is larger than the limit of %d bytes", size, limit)
+	}
+	return nil
+}
+
+// The space left for an unprivileged user on the file system of dir
+func FreeSpace(dir string) (int64, error) {
+	var stat unix.Statfs_t
+	err := unix.Statfs(dir, &stat)
+	if err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+// Copy exactly size bytes and stop early if ctx is done. The connection is closed
+// on cancellation since that is the only way to interrupt a blocking read or write
+func copyN(ctx context.Context, conn net.Conn, dst io.Writer, src io.Reader, size int64, progress Progress) error {
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	n, err := io.CopyN(&progressWriter{w: dst, progress: progress}, src, size)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: got %d of %d bytes", ErrShortTransfer, n, size)
+	}
+	return err
+}
+
+// An outgoing payload waiting for the other device to fetch it
+type Sender struct {
+	device   string
+	listener net.Listener
+	port     int
+}
+
+// Listen on the first free port in the payload range. Only device will be
+// allowed to fetch the payload
+func Listen(device string) (*Sender, error) {
+	for port := PortMin; port <= PortMax; port++ {
+		l, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+		if err == nil {
+			return &Sender{device: device, listener: l, port: port}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no free port in range %d-%d", PortMin, PortMax)
+}
+
+// The transfer info to put in the packet announcing the payload
+func (s *Sender) TransferInfo() *internal.PayloadTransferInfo {
+	return &internal.PayloadTransferInfo{Port: s.port}
+}
+
+func (s *Sender) Close() error {
+	return s.listener.Close()
+}
+
+// Wait for the device to connect and write size bytes from r to it.
+// The listener is closed when done
+func (s *Sender) Serve(ctx context.Context, r io.Reader, size int64, progress Progress) error {
+	defer s.listener.Close()
+
+	err := checkSize(size, 0)
+	if err != nil {
+		return err
+	}
+
+	// Accept blocks, so close the listener when giving up
+	acceptCtx, cancel := context.WithTimeout(ctx, AcceptTimeout)
+	stop := context.AfterFunc(acceptCtx, func() {
+		s.listener.Close()
+	})
+	conn, err := s.listener.Accept()
+	stop()
+	cancel()
+	if err != nil {
+		return fmt.Errorf("device did not fetch the payload: %w", err)
+	}
+	defer conn.Close()
+
+	// The side serving the payload is the tls server
+	tlsConn := tls.Server(conn, security.GetPinnedConfig(s.device))
+	err = tlsConn.HandshakeContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = copyN(ctx, tlsConn, tlsConn, r, size, progress)
+	if errors.Is(err, ErrShortTransfer) {
+		return fmt.Errorf("source is smaller than announced: %w", err)
+	}
+	return err
+}
+
+// Connect to the port announced by device at host and write the size bytes
+// of payload to w. Payloads larger than limit are refused before connecting,
+// the size is announced by the device so it has to be limited by the caller
+func Receive(ctx context.Context, device string, host string, info *internal.PayloadTransferInfo, size int64, limit int64, w io.Writer, progress Progress) error {
+	if info == nil {
+		return errors.New("packet has no payload")
+	}
+	if limit <= 0 {
+		return errors.New("no limit for the payload size")
+	}
+
+	err := checkSize(size, limit)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(info.Port))
+	dialer := tls.Dialer{Config: security.GetPinnedConfig(device)}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return copyN(ctx, conn, w, conn, size, progress)
+}
@@ -0,0 +1,183 @@
+package payload
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/security"
+)
+
+// Pair this computer with itself so that both ends of a transfer can run in
+// the test, the device is called self
+func pairWithSelf(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	cert, err := x509.ParseCertificate(config.GetCert().Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	security.Devices.Add("self", cert)
+}
+
+func otherCert(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "other"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// Serve r as a payload of size bytes to device in the background
+func serve(t *testing.T, ctx context.Context, device string, r io.Reader, size int64) (*Sender, <-chan error) {
+	sender, err := Listen(device)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- sender.Serve(ctx, r, size, nil)
+	}()
+	return sender, served
+}
+
+func TestTransfer(t *testing.T) {
+	pairWithSelf(t)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("gonnect"), 100000)
+	sender, served := serve(t, ctx, "self", bytes.NewReader(data), int64(len(data)))
+
+	var received bytes.Buffer
+	var transferred int64
+	err := Receive(ctx, "self", "127.0.0.1", sender.TransferInfo(), int64(len(data)), int64(len(data)), &received, func(n int64) {
+		transferred = n
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(received.Bytes(), data) {
+		t.Errorf("received %d bytes that differ from the %d sent", received.Len(), len(data))
+	}
+	if transferred != int64(len(data)) {
+		t.Errorf("progress ended at %d, want %d", transferred, len(data))
+	}
+}
+
+func TestShortTransfer(t *testing.T) {
+	pairWithSelf(t)
+	ctx := context.Background()
+
+	// Announce more than there is
+	data := []byte("short")
+	sender, served := serve(t, ctx, "self", bytes.NewReader(data), 100)
+
+	var received bytes.Buffer
+	err := Receive(ctx, "self", "127.0.0.1", sender.TransferInfo(), 100, 100, &received, nil)
+	if !errors.Is(err, ErrShortTransfer) {
+		t.Errorf("receive failed with %v, want %v", err, ErrShortTransfer)
+	}
+	if err := <-served; !errors.Is(err, ErrShortTransfer) {
+		t.Errorf("serve failed with %v, want %v", err, ErrShortTransfer)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	pairWithSelf(t)
+	ctx := context.Background()
+
+	sender, err := Listen("self")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Close()
+
+	var received bytes.Buffer
+	err = Receive(ctx, "self", "127.0.0.1", sender.TransferInfo(), 1000, 999, &received, nil)
+	if err == nil {
+		t.Error("payload larger than the limit was received")
+	}
+	err = Receive(ctx, "self", "127.0.0.1", sender.TransferInfo(), 1000, 0, &received, nil)
+	if err == nil {
+		t.Error("payload without a limit was received")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	pairWithSelf(t)
+
+	// The source sends one chunk and then stalls until the test is done
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write(make([]byte, 1024))
+
+	sender, served := serve(t, context.Background(), "self", r, 1<<20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var received bytes.Buffer
+	err := Receive(ctx, "self", "127.0.0.1", sender.TransferInfo(), 1<<20, 1<<20, &received, func(int64) {
+		// Cancel once the first data has arrived
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("receive failed with %v, want %v", err, context.Canceled)
+	}
+
+	w.CloseWithError(errors.New("test is done"))
+	if err := <-served; err == nil {
+		t.Error("serve did not fail when the receiver gave up")
+	}
+}
+
+func TestCertificateMismatch(t *testing.T) {
+	pairWithSelf(t)
+	ctx := context.Background()
+
+	// The certificate pinned for the device is not the one it presents
+	security.Devices.Add("other", otherCert(t))
+
+	data := []byte("secret")
+	sender, served := serve(t, ctx, "other", bytes.NewReader(data), int64(len(data)))
+
+	var received bytes.Buffer
+	err := Receive(ctx, "other", "127.0.0.1", sender.TransferInfo(), int64(len(data)), int64(len(data)), &received, nil)
+	if err == nil {
+		t.Error("payload was received from a device with the wrong certificate")
+	}
+	if err := <-served; err == nil {
+		t.Error("payload was served to a device with the wrong certificate")
+	}
+	if received.Len() != 0 {
+		t.Errorf("received %d bytes", received.Len())
+	}
+}
@@ -0,0 +1,146 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+)
+
+// The battery plugin reports the battery of this computer to the other device
+// and keeps track of the battery of the other device
+type batteryPlugin struct{}
+
+// The battery state of a device as reported to the cli
+type BatteryStatus struct {
+	Charge     int
+	IsCharging bool
+	Low        bool
+	Updated    time.Time
+}
+
+// How often the local battery is checked for changes
+const batteryPollInterval = time.Minute
+
+// Below this charge the battery is reported as low when not charging
+const batteryLowThreshold = 15
+
+// The last reported battery of each device, kept after the device disconnects
+var batteries = struct {
+	m map[string]BatteryStatus
+	sync.RWMutex
+}{m: make(map[string]BatteryStatus)}
+
+// Create a new battery plugin and start reporting the local battery
+func NewBatteryPlugin(ctx context.Context) *batteryPlugin {
+	b := batteryPlugin{}
+	go b.batteryWatcher(ctx)
+
+	return &b
+}
+
+// React implements GonnectPlugin.
+func (b *batteryPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectBattery]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	batteries.Lock()
+	batteries.m[device] = BatteryStatus{
+		Charge:     pkt.Body.CurrentCharge,
+		IsCharging: pkt.Body.IsCharging,
+		Low:        pkt.Body.ThresholdEvent == internal.BatteryThresholdLow,
+		Updated:    time.Now(),
+	}
+	batteries.Unlock()
+	slog.Debug("battery updated", "device", device, "charge", pkt.Body.CurrentCharge, "charging", pkt.Body.IsCharging)
+
+	return nil
+}
+
+// Send the local battery when connecting and then whenever it changes
+func (b *batteryPlugin) batteryWatcher(ctx context.Context) {
+	path := config.BatteryPath()
+	if path == "" {
+		slog.Debug("not reporting battery", "reason", "no battery")
+		return
+	}
+
+	c := connectionFromContext(ctx)
+	ticker := time.NewTicker(batteryPollInterval)
+	defer ticker.Stop()
+
+	var last *internal.GonnectBattery
+	for {
+		battery, err := readBattery(path)
+		if err != nil {
+			slog.Error("failed to read battery", "path", path, "error", err)
+		} else if last == nil || *last != battery {
+			err := c.Send(internal.NewGonnectPacket(battery))
+			if err != nil {
+				slog.Error("failed to send battery", "error", err)
+				return
+			}
+			last = &battery
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Read the battery state from sysfs
+func readBattery(path string) (internal.GonnectBattery, error) {
+	var battery internal.GonnectBattery
+
+	capacity, err := os.ReadFile(path + "/capacity")
+	if err != nil {
+		return battery, err
+	}
+	battery.CurrentCharge, err = strconv.Atoi(strings.TrimSpace(string(capacity)))
+	if err != nil {
+		return battery, err
+	}
+
+	status, err := os.ReadFile(path + "/status")
+	if err != nil {
+		return battery, err
+	}
+	// "Not charging" is reported when plugged in but held at a charge limit
+	switch strings.TrimSpace(string(status)) {
+	case "Charging", "Full", "Not charging":
+		battery.IsCharging = true
+	}
+
+	if !battery.IsCharging && battery.CurrentCharge <= batteryLowThreshold {
+		battery.ThresholdEvent = internal.BatteryThresholdLow
+	}
+
+	return battery, nil
+}
+
+// Get the last reported battery of a device
+func GetBattery(device string) (BatteryStatus, error) {
+	batteries.RLock()
+	defer batteries.RUnlock()
+
+	status, ok := batteries.m[device]
+	if !ok {
+		return status, fmt.Errorf("no battery reported by %q", device)
+	}
+	return status, nil
+}
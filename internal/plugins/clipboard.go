@@ -30,7 +30,7 @@ func NewClipboardPlugin(ctx context.Context, ch chan<- GonnectPluginMessage) *cl
 		panic(err)
 	}
 	// We dont need the NewChanMsg here since the slice does not live longer than this function
-	ch <- GonnectPluginMessage{Msg: data}
+	ch <- GonnectPluginMessage{ChanMsg: internal.ChanMsg{Msg: data}}
 
 	return &c
 }
@@ -57,13 +57,28 @@ func (c *clipboardPlugin) React(ctx context.Context, data []byte) any {
 		panic(err)
 	}
 
+	err = c.write(pkt.Body.Content)
+	if err != nil {
+		slog.Error("failed to write clipboard", "error", err)
+	}
+
+	return returnPacket
+}
+
+// Write to the desktop clipboard without sending it back to the other device
+func (c *clipboardPlugin) write(content string) error {
 	c.syncCh <- struct{}{}
 	cmd := exec.Command("wl-copy")
-	cmd.Stdin = bytes.NewReader([]byte(pkt.Body.Content))
-	cmd.Run()
-	slog.Debug("wrote clipboard", "content", pkt.Body.Content)
+	cmd.Stdin = bytes.NewReader([]byte(content))
+	err := cmd.Run()
+	if err != nil {
+		// Nothing will show up in the watcher so do not wait for it
+		<-c.syncCh
+		return err
+	}
+	slog.Debug("wrote clipboard", "content", content)
 
-	return returnPacket
+	return nil
 }
 
 // listen for clipboard changes and send to other device when notified about change
@@ -84,7 +99,7 @@ func (c *clipboardPlugin) clipboardWatcher(ctx context.Context, ch chan<- Gonnec
 		slog.Debug("killing clipboard watcher")
 		err = cmd.Process.Kill()
 		if err != nil {
-			slog.Error("error when killing clipboard watcher", err)
+			slog.Error("error when killing clipboard watcher", "error", err)
 		}
 	}()
 
@@ -115,7 +130,7 @@ func (c *clipboardPlugin) clipboardWatcher(ctx context.Context, ch chan<- Gonnec
 			default:
 				if msg.Err != nil {
 					slog.Error("error when reading in clipboard watcher", "error", msg.Err)
-					ch <- GonnectPluginMessage(internal.NewChanMsg(nil, msg.Err))
+					ch <- GonnectPluginMessage{ChanMsg: internal.NewChanMsg(nil, msg.Err)}
 					return
 				}
 
@@ -130,7 +145,7 @@ func (c *clipboardPlugin) clipboardWatcher(ctx context.Context, ch chan<- Gonnec
 				}
 
 				slog.Debug("sending clipboard", "data", string(msg.Msg))
-				ch <- GonnectPluginMessage(internal.NewChanMsg(data, nil))
+				ch <- GonnectPluginMessage{ChanMsg: internal.NewChanMsg(data, nil)}
 			}
 		}
 	}
@@ -0,0 +1,136 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/events"
+)
+
+// The contacts plugin keeps a copy of the contacts of the other device, only
+// the contacts that changed since the last sync are fetched
+type contactsPlugin struct {
+	// The timestamps of the contacts that have been requested but not received yet
+	requested map[string]int64
+	sync.Mutex
+}
+
+// Create a new contacts plugin and sync the contacts with the device
+func NewContactsPlugin(ctx context.Context) *contactsPlugin {
+	c := &contactsPlugin{requested: make(map[string]int64)}
+	go c.sync(ctx)
+
+	return c
+}
+
+func (c *contactsPlugin) sync(ctx context.Context) {
+	conn := connectionFromContext(ctx)
+	if !conn.Supports(internal.GonnectContactsRequestAllType) {
+		return
+	}
+
+	err := conn.Send(internal.NewGonnectPacket(internal.GonnectContactsRequestAll{}))
+	if err != nil {
+		slog.Error("failed to request contacts", "error", err)
+	}
+}
+
+// Both responses have the uids as keys, next to the list of uids
+func parseContactsResponse(data []byte) (map[string]json.RawMessage, []string, error) {
+	var pkt internal.GonnectPacket[map[string]json.RawMessage]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var uids []string
+	err = json.Unmarshal(pkt.Body["uids"], &uids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid uid list: %w", err)
+	}
+
+	valid := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if !validUid(uid) {
+			slog.Warn("ignoring contact with invalid uid", "uid", uid)
+			continue
+		}
+		valid = append(valid, uid)
+	}
+	return pkt.Body, valid, nil
+}
+
+// React implements GonnectPlugin.
+func (c *contactsPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[any]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	body, uids, err := parseContactsResponse(data)
+	if err != nil {
+		slog.Error("invalid contacts response", "device", device, "error", err)
+		return nil
+	}
+
+	switch pkt.Type {
+	case internal.GonnectContactsResponseUidsType:
+		timestamps := make(map[string]int64, len(uids))
+		for _, uid := range uids {
+			var timestamp int64
+			err := json.Unmarshal(body[uid], &timestamp)
+			if err != nil {
+				slog.Warn("invalid contact timestamp", "device", device, "uid", uid, "error", err)
+				continue
+			}
+			timestamps[uid] = timestamp
+		}
+
+		changed, err := changedContacts(device, timestamps)
+		if err != nil {
+			slog.Error("failed to update contacts", "device", device, "error", err)
+			return nil
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+
+		c.Lock()
+		for _, uid := range changed {
+			c.requested[uid] = timestamps[uid]
+		}
+		c.Unlock()
+
+		slog.Debug("requesting changed contacts", "device", device, "count", len(changed))
+		return internal.NewGonnectPacket(internal.GonnectContactsRequestVcards{Uids: changed})
+	case internal.GonnectContactsResponseVcardsType:
+		vcards := make(map[string]string, len(uids))
+		timestamps := make(map[string]int64, len(uids))
+		c.Lock()
+		for _, uid := range uids {
+			var vcard string
+			if json.Unmarshal(body[uid], &vcard) != nil {
+				continue
+			}
+			vcards[uid] = vcard
+			timestamps[uid] = c.requested[uid]
+			delete(c.requested, uid)
+		}
+		c.Unlock()
+
+		err := storeContacts(device, vcards, timestamps)
+		if err != nil {
+			slog.Error("failed to store contacts", "device", device, "error", err)
+			return nil
+		}
+		events.Publish(device, "contacts", fmt.Sprintf("synced %d contacts", len(vcards)))
+	}
+
+	return nil
+}
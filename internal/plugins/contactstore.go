@@ -0,0 +1,318 @@
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/blennster/gonnect/internal/config"
+)
+
+// A contact from the vcard store of a device
+type Contact struct {
+	Uid    string
+	Name   string
+	Phones []string
+	Emails []string
+}
+
+// The contacts of each device are stored as one vcard per contact in the data
+// directory, together with the timestamps of when they last changed
+var contactStore sync.Mutex
+
+func contactsDir(device string) string {
+	return filepath.Join(config.DataHome(), "contacts", device)
+}
+
+func contactTimestampsPath(device string) string {
+	return filepath.Join(contactsDir(device), "timestamps.json")
+}
+
+// The uids end up in paths so make sure they can not point anywhere else
+func validUid(uid string) bool {
+	return uid != "" && uid != "." && uid != ".." && !strings.ContainsAny(uid, "/\x00")
+}
+
+func readContactTimestamps(device string) (map[string]int64, error) {
+	timestamps := make(map[string]int64)
+
+	b, err := os.ReadFile(contactTimestampsPath(device))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return timestamps, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &timestamps)
+	return timestamps, err
+}
+
+func writeContactTimestamps(device string, timestamps map[string]int64) error {
+	b, err := json.Marshal(timestamps)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(contactTimestampsPath(device), b, 0600)
+}
+
+// Remove contacts that are no longer on the device and return the uids of the
+// contacts that are new or changed
+func changedContacts(device string, current map[string]int64) ([]string, error) {
+	contactStore.Lock()
+	defer contactStore.Unlock()
+
+	stored, err := readContactTimestamps(device)
+	if err != nil {
+		return nil, err
+	}
+
+	for uid := range stored {
+		if _, ok := current[uid]; ok {
+			continue
+		}
+		err := os.Remove(filepath.Join(contactsDir(device), uid+".vcf"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		delete(stored, uid)
+	}
+
+	changed := make([]string, 0)
+	for uid, timestamp := range current {
+		if ts, ok := stored[uid]; !ok || ts != timestamp {
+			changed = append(changed, uid)
+		}
+	}
+
+	err = os.MkdirAll(contactsDir(device), 0700)
+	if err != nil {
+		return nil, err
+	}
+	return changed, writeContactTimestamps(device, stored)
+}
+
+// Save the vcards of contacts with the timestamps they were requested for
+func storeContacts(device string, vcards map[string]string, timestamps map[string]int64) error {
+	contactStore.Lock()
+	defer contactStore.Unlock()
+
+	stored, err := readContactTimestamps(device)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(contactsDir(device), 0700)
+	if err != nil {
+		return err
+	}
+
+	for uid, vcard := range vcards {
+		err := os.WriteFile(filepath.Join(contactsDir(device), uid+".vcf"), []byte(vcard), 0600)
+		if err != nil {
+			return err
+		}
+		stored[uid] = timestamps[uid]
+	}
+
+	return writeContactTimestamps(device, stored)
+}
+
+// Get the contacts of a device sorted by name
+func GetContacts(device string) ([]Contact, error) {
+	contactStore.Lock()
+	defer contactStore.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(contactsDir(device), "*.vcf"))
+	if err != nil {
+		return nil, err
+	}
+
+	contacts := make([]Contact, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		contact := parseVcard(string(b))
+		contact.Uid = strings.TrimSuffix(filepath.Base(path), ".vcf")
+		contacts = append(contacts, contact)
+	}
+
+	sort.Slice(contacts, func(i, j int) bool {
+		return strings.ToLower(contacts[i].Name) < strings.ToLower(contacts[j].Name)
+	})
+	return contacts, nil
+}
+
+// Find the contacts of a device whose name, phone number or email contains query
+func SearchContacts(device string, query string) ([]Contact, error) {
+	contacts, err := GetContacts(device)
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	digits := phoneDigits(query)
+	found := make([]Contact, 0)
+	for _, c := range contacts {
+		if contactMatches(c, query, digits) {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+func contactMatches(c Contact, query string, digits string) bool {
+	if strings.Contains(strings.ToLower(c.Name), query) {
+		return true
+	}
+	for _, email := range c.Emails {
+		if strings.Contains(strings.ToLower(email), query) {
+			return true
+		}
+	}
+	if digits == "" {
+		return false
+	}
+	for _, phone := range c.Phones {
+		if strings.Contains(phoneDigits(phone), digits) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get all vcards of a device as a single vcard file
+func ExportContacts(device string) (string, error) {
+	contactStore.Lock()
+	defer contactStore.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(contactsDir(device), "*.vcf"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	var export strings.Builder
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		export.WriteString(strings.TrimRight(string(b), "\r\n"))
+		export.WriteString("\r\n")
+	}
+	return export.String(), nil
+}
+
+// Look up the name of the contact with a phone number, the number is returned
+// if there is no such contact
+func ContactName(device string, number string) string {
+	digits := phoneDigits(number)
+	// Too short to tell numbers apart, such as service numbers
+	if len(digits) < 3 {
+		return number
+	}
+
+	contacts, err := GetContacts(device)
+	if err != nil {
+		return number
+	}
+
+	for _, c := range contacts {
+		for _, phone := range c.Phones {
+			if samePhoneNumber(phoneDigits(phone), digits) {
+				return c.Name
+			}
+		}
+	}
+	return number
+}
+
+func phoneDigits(number string) string {
+	var digits strings.Builder
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
+
+// Numbers may be stored with or without the country code, so compare the end
+// of the numbers when they are long enough to be unique. Leading zeros are
+// dropped since they are replaced by the country code, as in 070 and +4670
+func samePhoneNumber(a string, b string) bool {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if a == b {
+		return true
+	}
+
+	const significant = 7
+	if len(a) < significant || len(b) < significant {
+		return false
+	}
+	return strings.HasSuffix(a, b) || strings.HasSuffix(b, a)
+}
+
+// Read the name, phone numbers and emails of a vcard
+func parseVcard(vcard string) Contact {
+	var contact Contact
+	var structuredName string
+
+	// Long lines are folded by starting the next line with whitespace
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(vcard))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	for _, line := range lines {
+		property, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop the parameters, such as TEL;TYPE=CELL
+		name, _, _ := strings.Cut(property, ";")
+		// And groups, such as item1.EMAIL
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+
+		switch strings.ToUpper(name) {
+		case "FN":
+			contact.Name = unescapeVcard(value)
+		case "N":
+			// Family;Given;Additional;Prefix;Suffix
+			parts := strings.Split(value, ";")
+			if len(parts) > 1 {
+				structuredName = strings.TrimSpace(unescapeVcard(parts[1]) + " " + unescapeVcard(parts[0]))
+			}
+		case "TEL":
+			contact.Phones = append(contact.Phones, strings.TrimPrefix(value, "tel:"))
+		case "EMAIL":
+			contact.Emails = append(contact.Emails, value)
+		}
+	}
+
+	if contact.Name == "" {
+		contact.Name = structuredName
+	}
+	return contact
+}
+
+func unescapeVcard(value string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, "\n", `\N`, "\n", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,121 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidUid(t *testing.T) {
+	tests := []struct {
+		uid  string
+		want bool
+	}{
+		{uid: "42", want: true},
+		{uid: "abc-def", want: true},
+		{uid: "..hidden", want: true},
+		{uid: "", want: false},
+		{uid: ".", want: false},
+		{uid: "..", want: false},
+		{uid: "../../etc/passwd", want: false},
+		{uid: "a/b", want: false},
+		{uid: "a\x00b", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			got := validUid(tt.uid)
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVcard(t *testing.T) {
+	tests := []struct {
+		name  string
+		vcard string
+		want  Contact
+	}{
+		{
+			name: "full",
+			vcard: "BEGIN:VCARD\r\nVERSION:2.1\r\nFN:Ada Lovelace\r\nN:Lovelace;Ada;;;\r\n" +
+				"TEL;CELL:+46 70 123 45 67\r\nTEL;HOME:tel:08-123456\r\nEMAIL;HOME:ada@example.com\r\nEND:VCARD\r\n",
+			want: Contact{
+				Name:   "Ada Lovelace",
+				Phones: []string{"+46 70 123 45 67", "08-123456"},
+				Emails: []string{"ada@example.com"},
+			},
+		},
+		{
+			name:  "structured name only",
+			vcard: "BEGIN:VCARD\nN:Hopper;Grace;;;\nEND:VCARD\n",
+			want:  Contact{Name: "Grace Hopper"},
+		},
+		{
+			name:  "escaped",
+			vcard: "BEGIN:VCARD\nFN:Smith\\, John\\; Jr\nEND:VCARD\n",
+			want:  Contact{Name: "Smith, John; Jr"},
+		},
+		{
+			name:  "folded line",
+			vcard: "BEGIN:VCARD\r\nFN:A very long\r\n  name\r\nEND:VCARD\r\n",
+			want:  Contact{Name: "A very long name"},
+		},
+		{
+			name:  "grouped property",
+			vcard: "BEGIN:VCARD\nFN:Bob\nitem1.EMAIL;TYPE=INTERNET:bob@example.com\nEND:VCARD\n",
+			want:  Contact{Name: "Bob", Emails: []string{"bob@example.com"}},
+		},
+		{
+			name:  "lowercase and unknown properties",
+			vcard: "begin:vcard\nfn:Eve\nnote:hello\nphoto;encoding=b:abc\nend:vcard\n",
+			want:  Contact{Name: "Eve"},
+		},
+		{
+			name:  "empty",
+			vcard: "",
+			want:  Contact{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseVcard(tt.vcard)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{name: "equal", a: "0701234567", b: "0701234567", want: true},
+		{name: "country code", a: "46701234567", b: "0701234567", want: true},
+		{name: "different", a: "0701234567", b: "0701234568", want: false},
+		{name: "short equal", a: "112", b: "112", want: true},
+		{name: "short suffix", a: "1234", b: "34", want: false},
+		{name: "leading zeros", a: "00112", b: "112", want: true},
+		{name: "too short for suffix", a: "461234567", b: "123456", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := samePhoneNumber(tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			// The comparison does not depend on the order
+			got = samePhoneNumber(tt.b, tt.a)
+			if got != tt.want {
+				t.Errorf("swapped: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sync"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/events"
+)
+
+// The find my phone plugin rings this computer when the other device asks for
+// it and lets the cli ring the other device
+type findMyPhonePlugin struct{}
+
+// There is only one computer to ring, so the state is shared by all connections
+var ringing = struct {
+	stop context.CancelFunc
+	sync.Mutex
+}{}
+
+// React implements GonnectPlugin.
+func (f *findMyPhonePlugin) React(ctx context.Context, data []byte) any {
+	device := connectionFromContext(ctx).Identity.DeviceId
+
+	// Like on the phone, asking again stops the ringing
+	if StopRinging() {
+		slog.Info("stopped ringing", "device", device)
+		return nil
+	}
+
+	slog.Info("ringing", "device", device)
+	events.Publish(device, "findmyphone", "device is looking for this computer")
+	startRinging()
+
+	return nil
+}
+
+// Play the ring sound over and over until stopped
+func startRinging() {
+	ringing.Lock()
+	defer ringing.Unlock()
+
+	// Not tied to the connection since the device may disconnect while ringing
+	ctx, cancel := context.WithCancel(context.Background())
+	ringing.stop = cancel
+
+	go func() {
+		settings := config.GetSettings()
+		player := settings.GetRingPlayer()
+		sound := settings.GetRingSound()
+
+		for ctx.Err() == nil {
+			cmd := exec.CommandContext(ctx, player[0], append(player[1:], sound)...)
+			err := cmd.Run()
+			if err != nil && ctx.Err() == nil {
+				// Stop instead of retrying since a missing player or sound will not fix itself
+				slog.Error("failed to play ring sound", "player", player[0], "sound", sound, "error", err)
+				StopRinging()
+				return
+			}
+		}
+	}()
+}
+
+// Stop ringing this computer, returns false if it was not ringing
+func StopRinging() bool {
+	ringing.Lock()
+	defer ringing.Unlock()
+
+	if ringing.stop == nil {
+		return false
+	}
+
+	ringing.stop()
+	ringing.stop = nil
+	return true
+}
+
+// Make a device play a sound so that it can be found
+func Ring(device string) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectFindMyPhoneType) {
+		return fmt.Errorf("device %q can not be rung", device)
+	}
+
+	return c.Send(internal.NewGonnectPacket(internal.GonnectFindMyPhone{}))
+}
@@ -0,0 +1,166 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/desktop"
+	"github.com/blennster/gonnect/internal/events"
+)
+
+// The lock plugin lets the other device lock this computer and see if it is
+// locked, and the other way around
+type lockPlugin struct{}
+
+// The last known lock state of each device
+var deviceLocks = struct {
+	m map[string]LockStatus
+	sync.RWMutex
+}{m: make(map[string]LockStatus)}
+
+type LockStatus struct {
+	Locked  bool
+	Updated time.Time
+}
+
+// Create a new lock plugin and start telling the device when this computer is
+// locked or unlocked
+func NewLockPlugin(ctx context.Context) *lockPlugin {
+	l := &lockPlugin{}
+	go l.lockWatcher(ctx)
+
+	return l
+}
+
+// React implements GonnectPlugin.
+func (l *lockPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[any]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+
+	switch pkt.Type {
+	case internal.GonnectLockType:
+		var pkt internal.GonnectPacket[internal.GonnectLock]
+		err := json.Unmarshal(data, &pkt)
+		if err != nil {
+			panic(err)
+		}
+
+		deviceLocks.Lock()
+		deviceLocks.m[device] = LockStatus{Locked: pkt.Body.IsLocked, Updated: time.Now()}
+		deviceLocks.Unlock()
+
+		state := "unlocked"
+		if pkt.Body.IsLocked {
+			state = "locked"
+		}
+		events.Publish(device, "lock", "device "+state)
+	case internal.GonnectLockRequestType:
+		var pkt internal.GonnectPacket[internal.GonnectLockRequest]
+		err := json.Unmarshal(data, &pkt)
+		if err != nil {
+			panic(err)
+		}
+
+		if pkt.Body.SetLocked != nil {
+			// Unlocking from another device is not allowed, it would make a
+			// lost phone enough to get into this computer
+			if !*pkt.Body.SetLocked {
+				slog.Warn("ignoring request to unlock", "device", device)
+				return nil
+			}
+
+			err := Lock()
+			if err != nil {
+				slog.Error("failed to lock session", "error", err)
+				return nil
+			}
+			events.Publish(device, "lock", "locked this computer")
+		}
+
+		if pkt.Body.RequestLocked {
+			locked, err := desktop.SessionLocked()
+			if err != nil {
+				slog.Error("failed to get lock state", "error", err)
+				return nil
+			}
+			return internal.NewGonnectPacket(internal.GonnectLock{IsLocked: locked})
+		}
+	}
+
+	return nil
+}
+
+// Tell the device when this computer is locked or unlocked
+func (l *lockPlugin) lockWatcher(ctx context.Context) {
+	c := connectionFromContext(ctx)
+	if !c.Supports(internal.GonnectLockType) {
+		return
+	}
+
+	changes, err := desktop.WatchLocked(ctx)
+	if err != nil {
+		slog.Warn("not watching lock state", "error", err)
+		return
+	}
+
+	for locked := range changes {
+		err := c.Send(internal.NewGonnectPacket(internal.GonnectLock{IsLocked: locked}))
+		if err != nil {
+			slog.Error("failed to send lock state", "error", err)
+			return
+		}
+	}
+}
+
+// Lock this computer with the configured command or through logind
+func Lock() error {
+	command := config.GetSettings().GetLockCommand()
+	if len(command) == 0 {
+		return desktop.LockSession()
+	}
+
+	out, err := exec.Command(command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", command[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Lock a device
+func LockDevice(device string) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectLockRequestType) {
+		return fmt.Errorf("device %q can not be locked", device)
+	}
+
+	locked := true
+	return c.Send(internal.NewGonnectPacket(internal.GonnectLockRequest{SetLocked: &locked}))
+}
+
+// Get the last lock state a device sent
+func GetLockStatus(device string) (LockStatus, error) {
+	deviceLocks.RLock()
+	defer deviceLocks.RUnlock()
+
+	status, ok := deviceLocks.m[device]
+	if !ok {
+		return LockStatus{}, fmt.Errorf("no lock state known for %q", device)
+	}
+	return status, nil
+}
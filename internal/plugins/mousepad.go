@@ -0,0 +1,192 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/input"
+	"github.com/blennster/gonnect/internal/security"
+)
+
+// The mousepad plugin lets a device control the pointer and keyboard of this
+// computer, if the device has been allowed to
+type mousepadPlugin struct {
+	// Pointer motion is sent as fractions of pixels, the rest is kept for the next motion
+	restX float64
+	restY float64
+	sync.Mutex
+}
+
+var mousepadSpecialKeys = map[int]input.SpecialKey{
+	internal.MousepadKeyBackspace:  input.KeyBackspace,
+	internal.MousepadKeyTab:        input.KeyTab,
+	internal.MousepadKeyLinefeed:   input.KeyLinefeed,
+	internal.MousepadKeyLeft:       input.KeyLeft,
+	internal.MousepadKeyUp:         input.KeyUp,
+	internal.MousepadKeyRight:      input.KeyRight,
+	internal.MousepadKeyDown:       input.KeyDown,
+	internal.MousepadKeyPageUp:     input.KeyPageUp,
+	internal.MousepadKeyPageDown:   input.KeyPageDown,
+	internal.MousepadKeyHome:       input.KeyHome,
+	internal.MousepadKeyEnd:        input.KeyEnd,
+	internal.MousepadKeyEnter:      input.KeyEnter,
+	internal.MousepadKeyDelete:     input.KeyDelete,
+	internal.MousepadKeyEscape:     input.KeyEscape,
+	internal.MousepadKeySysReq:     input.KeySysReq,
+	internal.MousepadKeyScrollLock: input.KeyScrollLock,
+}
+
+func init() {
+	for i := 0; i <= internal.MousepadKeyF12-internal.MousepadKeyF1; i++ {
+		mousepadSpecialKeys[internal.MousepadKeyF1+i] = input.KeyF1 + input.SpecialKey(i)
+	}
+}
+
+// Create a new mousepad plugin and tell the device if it may send input
+func NewMousepadPlugin(ctx context.Context) *mousepadPlugin {
+	m := &mousepadPlugin{}
+	go m.sendKeyboardState(ctx)
+
+	return m
+}
+
+func (m *mousepadPlugin) sendKeyboardState(ctx context.Context) {
+	c := connectionFromContext(ctx)
+	if !c.Supports(internal.GonnectMousepadKeyboardStateType) {
+		return
+	}
+
+	allowed := security.Devices.HasPermission(c.Identity.DeviceId, security.PermissionRemoteInput)
+	err := c.Send(internal.NewGonnectPacket(internal.GonnectMousepadKeyboardState{State: allowed}))
+	if err != nil {
+		slog.Error("failed to send keyboard state", "error", err)
+	}
+}
+
+// React implements GonnectPlugin.
+func (m *mousepadPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectMousepadRequest]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	if !security.Devices.HasPermission(device, security.PermissionRemoteInput) {
+		slog.Warn("device is not allowed to send input", "device", device)
+		return nil
+	}
+
+	backend, err := input.Get()
+	if err != nil {
+		slog.Error("failed to get input backend", "error", err)
+		return nil
+	}
+
+	err = m.handle(backend, pkt.Body)
+	if err != nil {
+		slog.Error("failed to inject input", "device", device, "error", err)
+		return nil
+	}
+
+	if pkt.Body.SendAck {
+		return internal.NewGonnectPacket(internal.GonnectMousepadEcho{GonnectMousepadRequest: pkt.Body, IsAck: true})
+	}
+	return nil
+}
+
+func (m *mousepadPlugin) handle(backend input.Backend, req internal.GonnectMousepadRequest) error {
+	mods := input.Modifiers{Shift: req.Shift, Ctrl: req.Ctrl, Alt: req.Alt, Super: req.Super}
+
+	switch {
+	case req.Key != "":
+		if mods == (input.Modifiers{}) {
+			return backend.Type(req.Key)
+		}
+		// Shortcuts such as ctrl+c
+		var errs []error
+		for _, r := range req.Key {
+			errs = append(errs, backend.Key(input.Key{Char: r}, mods))
+		}
+		return errors.Join(errs...)
+	case req.SpecialKey != 0:
+		key, ok := mousepadSpecialKeys[req.SpecialKey]
+		if !ok {
+			slog.Debug("ignoring unknown special key", "key", req.SpecialKey)
+			return nil
+		}
+		return backend.Key(input.Key{Special: key}, mods)
+	case req.SingleClick:
+		return input.Click(backend, input.ButtonLeft)
+	case req.DoubleClick:
+		err := input.Click(backend, input.ButtonLeft)
+		if err != nil {
+			return err
+		}
+		return input.Click(backend, input.ButtonLeft)
+	case req.MiddleClick:
+		return input.Click(backend, input.ButtonMiddle)
+	case req.RightClick:
+		return input.Click(backend, input.ButtonRight)
+	case req.SingleHold:
+		return backend.Press(input.ButtonLeft)
+	case req.SingleRelease:
+		return backend.Release(input.ButtonLeft)
+	case req.Scroll:
+		// One step per packet, the phone sends a packet for every step
+		return backend.Scroll(int(sign(req.Dx)), int(sign(req.Dy)))
+	case req.Dx != 0 || req.Dy != 0:
+		dx, dy := m.motion(req.Dx, req.Dy)
+		if dx == 0 && dy == 0 {
+			return nil
+		}
+		return backend.Move(dx, dy)
+	}
+
+	return nil
+}
+
+// Add the motion to what is left from earlier motions and get the whole pixels
+func (m *mousepadPlugin) motion(dx float64, dy float64) (int, int) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.restX += dx
+	m.restY += dy
+	x := math.Trunc(m.restX)
+	y := math.Trunc(m.restY)
+	m.restX -= x
+	m.restY -= y
+	return int(x), int(y)
+}
+
+func sign(f float64) float64 {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	}
+	return 0
+}
+
+// Allow or deny a device to control the pointer and keyboard. The device is
+// told right away if it is connected
+func SetRemoteInputPermission(device string, allowed bool) error {
+	err := security.Devices.SetPermission(device, security.PermissionRemoteInput, allowed)
+	if err != nil {
+		return err
+	}
+
+	c, err := GetConnection(device)
+	if err != nil {
+		return nil
+	}
+	c.ctx.Value(internal.GonnectMousepadRequestType).(*mousepadPlugin).sendKeyboardState(c.ctx)
+	return nil
+}
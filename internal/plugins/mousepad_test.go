@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/input"
+)
+
+func TestMousepadHandle(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    internal.GonnectMousepadRequest
+		events []input.Event
+	}{
+		{
+			name:   "text",
+			req:    internal.GonnectMousepadRequest{Key: "hi"},
+			events: []input.Event{{Kind: "type", Text: "hi"}},
+		},
+		{
+			name: "shortcut",
+			req:  internal.GonnectMousepadRequest{Key: "c", Ctrl: true},
+			events: []input.Event{
+				{Kind: "key", Key: input.Key{Char: 'c'}, Modifiers: input.Modifiers{Ctrl: true}},
+			},
+		},
+		{
+			name:   "special key",
+			req:    internal.GonnectMousepadRequest{SpecialKey: internal.MousepadKeyF1 + 4},
+			events: []input.Event{{Kind: "key", Key: input.Key{Special: input.KeyF5}}},
+		},
+		{
+			name:   "unknown special key",
+			req:    internal.GonnectMousepadRequest{SpecialKey: 18},
+			events: nil,
+		},
+		{
+			name: "double click",
+			req:  internal.GonnectMousepadRequest{DoubleClick: true},
+			events: []input.Event{
+				{Kind: "press", Button: input.ButtonLeft},
+				{Kind: "release", Button: input.ButtonLeft},
+				{Kind: "press", Button: input.ButtonLeft},
+				{Kind: "release", Button: input.ButtonLeft},
+			},
+		},
+		{
+			name: "right click",
+			req:  internal.GonnectMousepadRequest{RightClick: true},
+			events: []input.Event{
+				{Kind: "press", Button: input.ButtonRight},
+				{Kind: "release", Button: input.ButtonRight},
+			},
+		},
+		{
+			name:   "scroll",
+			req:    internal.GonnectMousepadRequest{Scroll: true, Dy: -3.5},
+			events: []input.Event{{Kind: "scroll", X: 0, Y: -1}},
+		},
+		{
+			name:   "move",
+			req:    internal.GonnectMousepadRequest{Dx: 2.5, Dy: -1},
+			events: []input.Event{{Kind: "move", X: 2, Y: -1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var recorder input.Recorder
+			m := &mousepadPlugin{}
+			err := m.handle(&recorder, test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			events := recorder.Take()
+			if !reflect.DeepEqual(events, test.events) {
+				t.Errorf("got %+v, want %+v", events, test.events)
+			}
+		})
+	}
+}
+
+func TestMousepadMotionRest(t *testing.T) {
+	var recorder input.Recorder
+	m := &mousepadPlugin{}
+
+	// The fractions add up to a whole pixel on the third motion
+	for i := 0; i < 3; i++ {
+		err := m.handle(&recorder, internal.GonnectMousepadRequest{Dx: 0.4})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := recorder.Take()
+	want := []input.Event{{Kind: "move", X: 1, Y: 0}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got %+v, want %+v", events, want)
+	}
+}
@@ -0,0 +1,219 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/desktop"
+)
+
+// The mpris plugin lets the other device control the media players of this computer
+type mprisPlugin struct{}
+
+// Create a new mpris plugin and start sending player changes to the other device
+func NewMprisPlugin(ctx context.Context) *mprisPlugin {
+	m := mprisPlugin{}
+	go m.playerWatcher(ctx)
+
+	return &m
+}
+
+// React implements GonnectPlugin.
+func (m *mprisPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectMprisRequest]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	if pkt.Body.RequestPlayerList {
+		list, err := playerList()
+		if err != nil {
+			slog.Error("failed to list media players", "error", err)
+			return nil
+		}
+		return list
+	}
+
+	if pkt.Body.Player == "" {
+		return nil
+	}
+
+	player, err := desktop.FindPlayer(pkt.Body.Player)
+	if err != nil {
+		slog.Warn("media player not found", "player", pkt.Body.Player, "error", err)
+		return nil
+	}
+
+	if pkt.Body.AlbumArtUrl != "" {
+		// Reading and sending the file can take a while
+		go func() {
+			err := m.sendAlbumArt(ctx, player, pkt.Body.AlbumArtUrl)
+			if err != nil {
+				slog.Error("failed to send album art", "player", player.Name, "url", pkt.Body.AlbumArtUrl, "error", err)
+			}
+		}()
+		return nil
+	}
+
+	err = m.control(player, pkt.Body)
+	if err != nil {
+		slog.Error("failed to control media player", "player", player.Name, "error", err)
+	}
+
+	if pkt.Body.RequestNowPlaying || pkt.Body.RequestVolume {
+		state, err := playerState(player)
+		if err != nil {
+			slog.Error("failed to get media player state", "player", player.Name, "error", err)
+			return nil
+		}
+		return state
+	}
+
+	return nil
+}
+
+// Carry out the actions in a request
+func (m *mprisPlugin) control(player desktop.Player, req internal.GonnectMprisRequest) error {
+	if req.Action != "" {
+		err := player.Action(req.Action)
+		if err != nil {
+			return err
+		}
+	}
+
+	if req.SetVolume != nil {
+		err := player.SetVolume(*req.SetVolume)
+		if err != nil {
+			return err
+		}
+	}
+
+	if req.Seek != 0 {
+		// The request is in microseconds
+		err := player.SeekBy(req.Seek / 1000)
+		if err != nil {
+			return err
+		}
+	}
+
+	if req.SetPosition != nil {
+		err := player.SetPosition(*req.SetPosition)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Send the album art of the current track as a payload. Only the art of the
+// current track is sent so that the other device can not ask for any file
+func (m *mprisPlugin) sendAlbumArt(ctx context.Context, player desktop.Player, artUrl string) error {
+	state, err := player.State()
+	if err != nil {
+		return err
+	}
+	if state.ArtUrl != artUrl {
+		return fmt.Errorf("not the album art of the current track")
+	}
+
+	u, err := url.Parse(artUrl)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "file" {
+		return fmt.Errorf("only local album art can be sent")
+	}
+
+	f, err := os.Open(u.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	pkt := internal.NewGonnectPacket(internal.GonnectMpris{
+		Player:               player.Name,
+		AlbumArtUrl:          artUrl,
+		TransferringAlbumArt: true,
+	})
+	return sendWithPayload(ctx, pkt, f, info.Size(), nil)
+}
+
+// Send the state of players when it changes and the player list when players come and go
+func (m *mprisPlugin) playerWatcher(ctx context.Context) {
+	changes, err := desktop.WatchPlayers(ctx)
+	if err != nil {
+		slog.Warn("not watching media players", "error", err)
+		return
+	}
+
+	c := connectionFromContext(ctx)
+	for change := range changes {
+		var pkt any
+		if change.Player != nil {
+			pkt, err = playerState(*change.Player)
+		} else {
+			pkt, err = playerList()
+		}
+		if err != nil {
+			slog.Debug("failed to get media player change", "error", err)
+			continue
+		}
+
+		err = c.Send(pkt)
+		if err != nil {
+			slog.Error("failed to send media player change", "error", err)
+			return
+		}
+	}
+}
+
+func playerList() (internal.GonnectPacket[internal.GonnectMprisPlayerList], error) {
+	players, err := desktop.Players()
+	if err != nil {
+		return internal.GonnectPacket[internal.GonnectMprisPlayerList]{}, err
+	}
+
+	// Not nil, so that no players is sent as an empty list
+	names := make([]string, 0, len(players))
+	for _, p := range players {
+		names = append(names, p.Name)
+	}
+
+	return internal.NewGonnectPacket(internal.GonnectMprisPlayerList{PlayerList: names, SupportAlbumArtPayload: true}), nil
+}
+
+func playerState(player desktop.Player) (internal.GonnectPacket[internal.GonnectMpris], error) {
+	state, err := player.State()
+	if err != nil {
+		return internal.GonnectPacket[internal.GonnectMpris]{}, err
+	}
+
+	return internal.NewGonnectPacket(internal.GonnectMpris{
+		Player:        player.Name,
+		Title:         state.Title,
+		Artist:        state.Artist,
+		Album:         state.Album,
+		AlbumArtUrl:   state.ArtUrl,
+		IsPlaying:     state.IsPlaying,
+		Pos:           state.Position,
+		Length:        state.Length,
+		Volume:        state.Volume,
+		CanPlay:       state.CanPlay,
+		CanPause:      state.CanPause,
+		CanGoNext:     state.CanGoNext,
+		CanGoPrevious: state.CanGoPrevious,
+		CanSeek:       state.CanSeek,
+	}), nil
+}
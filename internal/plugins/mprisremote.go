@@ -0,0 +1,233 @@
+package plugins
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+)
+
+// The mpris remote plugin keeps track of the media players of the other device
+// so that they can be controlled from the cli
+type mprisRemotePlugin struct{}
+
+// A media player on another device
+type RemotePlayer struct {
+	internal.GonnectMpris
+	// When the state was received, the position is only correct at this time
+	Updated time.Time
+	// Where the album art of the current track is cached, empty if it has not been received
+	AlbumArtPath string
+}
+
+type remoteMedia struct {
+	// The names of the players in the order the device sent them
+	list    []string
+	players map[string]RemotePlayer
+	// The device can send album art, it says so with the player list
+	artPayload bool
+	// Album art urls that have been asked for, to only ask once
+	requestedArt map[string]bool
+}
+
+// Allow the album art to be asked for again after a failed transfer
+func forgetRequestedArt(device string, artUrl string) {
+	remotePlayers.Lock()
+	defer remotePlayers.Unlock()
+
+	if media, ok := remotePlayers.m[device]; ok {
+		delete(media.requestedArt, artUrl)
+	}
+}
+
+// The last known media players of each device, kept after the device disconnects
+var remotePlayers = struct {
+	m map[string]*remoteMedia
+	sync.RWMutex
+}{m: make(map[string]*remoteMedia)}
+
+// Create a new mpris remote plugin and ask the other device for its players
+func NewMprisRemotePlugin(ctx context.Context) *mprisRemotePlugin {
+	go func() {
+		c := connectionFromContext(ctx)
+		if !c.Supports(internal.GonnectMprisRequestType) {
+			return
+		}
+
+		err := c.Send(internal.NewGonnectPacket(internal.GonnectMprisRequest{RequestPlayerList: true}))
+		if err != nil {
+			slog.Error("failed to request media players", "error", err)
+		}
+	}()
+
+	return &mprisRemotePlugin{}
+}
+
+// React implements GonnectPlugin.
+func (m *mprisRemotePlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectMpris]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	c := connectionFromContext(ctx)
+	device := c.Identity.DeviceId
+
+	remotePlayers.Lock()
+	defer remotePlayers.Unlock()
+
+	media, ok := remotePlayers.m[device]
+	if !ok {
+		media = &remoteMedia{players: make(map[string]RemotePlayer), requestedArt: make(map[string]bool)}
+		remotePlayers.m[device] = media
+	}
+
+	if pkt.Body.PlayerList != nil {
+		media.list = pkt.Body.PlayerList
+		media.artPayload = pkt.Body.SupportAlbumArtPayload
+		for name := range media.players {
+			if !slices.Contains(media.list, name) {
+				delete(media.players, name)
+			}
+		}
+
+		// Sending may block, so ask for the state of the players without holding the lock
+		go func(players []string) {
+			for _, player := range players {
+				err := c.Send(internal.NewGonnectPacket(internal.GonnectMprisRequest{
+					Player:            player,
+					RequestNowPlaying: true,
+					RequestVolume:     true,
+				}))
+				if err != nil {
+					slog.Error("failed to request media player state", "player", player, "error", err)
+					return
+				}
+			}
+		}(pkt.Body.PlayerList)
+	}
+
+	if pkt.Body.TransferringAlbumArt {
+		if pkt.PayloadTransferInfo != nil {
+			go func() {
+				err := m.receiveAlbumArt(ctx, pkt)
+				if err != nil {
+					slog.Error("failed to receive album art", "url", pkt.Body.AlbumArtUrl, "error", err)
+					forgetRequestedArt(device, pkt.Body.AlbumArtUrl)
+				}
+			}()
+		}
+		// The packet only carries the art, not the state of the player
+		return nil
+	}
+
+	if pkt.Body.Player != "" {
+		media.players[pkt.Body.Player] = RemotePlayer{GonnectMpris: pkt.Body, Updated: time.Now()}
+
+		artUrl := pkt.Body.AlbumArtUrl
+		artPayload := media.artPayload || pkt.Body.SupportAlbumArtPayload
+		if artPayload && artUrl != "" && !media.requestedArt[artUrl] && !fileExists(albumArtPath(device, artUrl)) {
+			media.requestedArt[artUrl] = true
+			go func() {
+				err := c.Send(internal.NewGonnectPacket(internal.GonnectMprisRequest{
+					Player:      pkt.Body.Player,
+					AlbumArtUrl: artUrl,
+				}))
+				if err != nil {
+					slog.Error("failed to request album art", "url", artUrl, "error", err)
+					forgetRequestedArt(device, artUrl)
+				}
+			}()
+		}
+	}
+
+	return nil
+}
+
+// Album art is cached per device by the hash of its url since the url is
+// only meaningful to the device
+func albumArtPath(device string, artUrl string) string {
+	hash := sha256.Sum256([]byte(artUrl))
+	return config.CacheHome() + "/albumart/" + device + "/" + hex.EncodeToString(hash[:])
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (m *mprisRemotePlugin) receiveAlbumArt(ctx context.Context, pkt internal.GonnectPacket[internal.GonnectMpris]) error {
+	device := connectionFromContext(ctx).Identity.DeviceId
+	path := albumArtPath(device, pkt.Body.AlbumArtUrl)
+
+	err := receivePayloadToFile(ctx, pkt, maxImageSize, path)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("received album art", "device", device, "path", path)
+	return nil
+}
+
+// Get the media players of a device in the order the device sent them
+func GetRemotePlayers(device string) ([]RemotePlayer, error) {
+	remotePlayers.RLock()
+	defer remotePlayers.RUnlock()
+
+	media, ok := remotePlayers.m[device]
+	if !ok {
+		return nil, fmt.Errorf("no media players reported by %q", device)
+	}
+
+	players := make([]RemotePlayer, 0, len(media.list))
+	for _, name := range media.list {
+		if p, ok := media.players[name]; ok {
+			if p.AlbumArtUrl != "" && fileExists(albumArtPath(device, p.AlbumArtUrl)) {
+				p.AlbumArtPath = albumArtPath(device, p.AlbumArtUrl)
+			}
+			players = append(players, p)
+		} else {
+			// The state has not arrived yet
+			players = append(players, RemotePlayer{GonnectMpris: internal.GonnectMpris{Player: name}})
+		}
+	}
+	return players, nil
+}
+
+// Send a request to control a media player on a device. If no player is
+// given the first one is used
+func ControlRemotePlayer(device string, req internal.GonnectMprisRequest) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectMprisRequestType) {
+		return fmt.Errorf("device %q does not accept media control", device)
+	}
+
+	if req.Player == "" {
+		players, err := GetRemotePlayers(device)
+		if err != nil {
+			return err
+		}
+		if len(players) == 0 {
+			return fmt.Errorf("device %q has no media players", device)
+		}
+		req.Player = players[0].Player
+	}
+
+	// Always ask for the new state so that the status is up to date
+	req.RequestNowPlaying = true
+	req.RequestVolume = true
+	return c.Send(internal.NewGonnectPacket(req))
+}
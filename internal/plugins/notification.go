@@ -0,0 +1,390 @@
+package plugins
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/desktop"
+	"github.com/blennster/gonnect/internal/events"
+	"github.com/google/uuid"
+)
+
+// The notification plugin shows notifications from the other device on the desktop
+// and lets the user reply to, act on and dismiss them. Desktop notifications can
+// also be mirrored to the other device
+type notificationPlugin struct {
+	// The notifications currently on the other device, keyed by their id
+	notifications map[string]internal.GonnectNotification
+	// Maps the notification ids of the other device to the ids of the desktop notifications
+	shown map[string]uint32
+	sync.Mutex
+}
+
+// Create a new notification plugin and start listening for clicked notification buttons
+func NewNotificationPlugin(ctx context.Context) *notificationPlugin {
+	n := &notificationPlugin{
+		notifications: make(map[string]internal.GonnectNotification),
+		shown:         make(map[string]uint32),
+	}
+	go n.actionWatcher(ctx)
+	go n.sync(ctx)
+	if config.GetSettings().MirrorNotifications {
+		go n.notificationMirror(ctx)
+	}
+
+	return n
+}
+
+// Ask for the notifications already on the device and tell it about the ones
+// dismissed while it was away
+func (n *notificationPlugin) sync(ctx context.Context) {
+	c := connectionFromContext(ctx)
+
+	err := c.Send(internal.NewGonnectPacket(internal.GonnectNotificationRequest{Request: true}))
+	if err != nil {
+		slog.Error("failed to request notifications", "error", err)
+		return
+	}
+
+	ids, err := takePendingDismissals(c.Identity.DeviceId)
+	if err != nil {
+		slog.Error("failed to read notification log", "error", err)
+		return
+	}
+	for _, id := range ids {
+		err := c.Send(internal.NewGonnectPacket(internal.GonnectNotificationRequest{Cancel: id}))
+		if err != nil {
+			slog.Error("failed to dismiss notification", "id", id, "error", err)
+		}
+	}
+}
+
+// React implements GonnectPlugin.
+func (n *notificationPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectNotification]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+
+	if pkt.Body.IsCancel {
+		n.close(pkt.Body.Id)
+		// It is fine if it was never logged
+		dismissLoggedNotification(device, pkt.Body.Id, false)
+		return nil
+	}
+
+	n.Lock()
+	n.notifications[pkt.Body.Id] = pkt.Body
+	n.Unlock()
+
+	err = logNotification(device, pkt.Body)
+	if err != nil {
+		slog.Error("failed to log notification", "error", err)
+	}
+
+	// Already existing notifications are sent when connecting and should not pop up again
+	if pkt.Body.Silent {
+		return nil
+	}
+
+	events.Publish(device, "notification", fmt.Sprintf("%s (id %s): %s", pkt.Body.AppName, pkt.Body.Id, pkt.Body.Title))
+
+	// Fetching the icon may take a while so do not block the connection
+	go n.show(ctx, pkt)
+
+	return nil
+}
+
+func (n *notificationPlugin) show(ctx context.Context, pkt internal.GonnectPacket[internal.GonnectNotification]) {
+	icon := ""
+	if pkt.PayloadTransferInfo != nil {
+		var err error
+		icon, err = n.fetchIcon(ctx, pkt)
+		if err != nil {
+			slog.Warn("failed to fetch notification icon", "error", err)
+		}
+	}
+
+	title := pkt.Body.Title
+	if title == "" {
+		title = pkt.Body.AppName
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	// It may have been dismissed while fetching the icon
+	if _, ok := n.notifications[pkt.Body.Id]; !ok {
+		return
+	}
+
+	id, err := desktop.Notify(desktop.Notification{
+		AppName:    pkt.Body.AppName,
+		Title:      title,
+		Body:       pkt.Body.Text,
+		Icon:       icon,
+		ReplacesId: n.shown[pkt.Body.Id],
+		Actions:    pkt.Body.Actions,
+	})
+	if err != nil {
+		slog.Error("failed to show notification", "error", err)
+		return
+	}
+	n.shown[pkt.Body.Id] = id
+}
+
+func (n *notificationPlugin) close(id string) {
+	n.Lock()
+	defer n.Unlock()
+
+	delete(n.notifications, id)
+	desktopId, ok := n.shown[id]
+	if !ok {
+		return
+	}
+	delete(n.shown, id)
+
+	err := desktop.CloseNotification(desktopId)
+	if err != nil {
+		slog.Error("failed to close notification", "error", err)
+	}
+}
+
+// Forward clicks on notification buttons to the other device
+func (n *notificationPlugin) actionWatcher(ctx context.Context) {
+	actions, err := desktop.WatchActions(ctx)
+	if err != nil {
+		slog.Warn("not watching notification actions", "error", err)
+		return
+	}
+
+	c := connectionFromContext(ctx)
+	for a := range actions {
+		id := ""
+		n.Lock()
+		for phoneId, desktopId := range n.shown {
+			if desktopId == a.Id {
+				id = phoneId
+				break
+			}
+		}
+		n.Unlock()
+
+		// The notification belongs to something else
+		if id == "" {
+			continue
+		}
+
+		err := c.Send(internal.NewGonnectPacket(internal.GonnectNotificationAction{Key: id, Action: a.Action}))
+		if err != nil {
+			slog.Error("failed to send notification action", "error", err)
+		}
+	}
+}
+
+// Send the notifications shown on the desktop to the other device
+func (n *notificationPlugin) notificationMirror(ctx context.Context) {
+	c := connectionFromContext(ctx)
+	if !c.Supports(internal.GonnectNotificationType) {
+		slog.Debug("not mirroring notifications", "reason", "not supported by device", "device", c.Identity.DeviceId)
+		return
+	}
+
+	events, err := subscribeMirror(ctx)
+	if err != nil {
+		slog.Error("failed to watch desktop notifications", "error", err)
+		return
+	}
+	slog.Debug("notification mirror started", "device", c.Identity.DeviceId)
+
+	// Maps the ids of the desktop notifications to the ids sent to the device,
+	// so that updated notifications replace the earlier one on the device
+	mirrored := make(map[uint32]string)
+	for {
+		var event mirrorEvent
+		select {
+		case event = <-events:
+		case <-ctx.Done():
+			return
+		}
+
+		if event.closed != 0 {
+			sent, ok := mirrored[event.closed]
+			if !ok {
+				continue
+			}
+			delete(mirrored, event.closed)
+
+			err := c.Send(internal.NewGonnectPacket(internal.GonnectNotification{Id: sent, IsCancel: true}))
+			if err != nil {
+				slog.Error("failed to cancel mirrored notification", "error", err)
+				return
+			}
+			continue
+		}
+
+		notification := event.notification
+		// Read every time so that the lists can be changed while running
+		if !config.GetSettings().ShouldMirror(notification.AppName) {
+			slog.Debug("not mirroring notification", "app", notification.AppName)
+			continue
+		}
+
+		pkt := newOutgoingNotification(notification.AppName, notification.Title, notification.Body)
+		if id, ok := mirrored[notification.Id]; ok {
+			pkt.Body.Id = id
+		}
+		if notification.Id != 0 {
+			mirrored[notification.Id] = pkt.Body.Id
+		}
+
+		err := c.Send(pkt)
+		if err != nil {
+			slog.Error("failed to mirror notification", "error", err)
+			return
+		}
+	}
+}
+
+// Create a notification packet to show on the other device
+func newOutgoingNotification(app string, title string, text string) internal.GonnectPacket[internal.GonnectNotification] {
+	return internal.NewGonnectPacket(internal.GonnectNotification{
+		Id:          "gonnect-" + uuid.NewString(),
+		AppName:     app,
+		Title:       title,
+		Text:        text,
+		Ticker:      title + ": " + text,
+		Time:        strconv.FormatInt(time.Now().UnixMilli(), 10),
+		IsClearable: true,
+	})
+}
+
+// Show a notification on a device
+func SendNotification(device string, app string, title string, text string) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectNotificationType) {
+		return fmt.Errorf("device %q does not accept notifications", device)
+	}
+
+	if app == "" {
+		app = "gonnect"
+	}
+	return c.Send(newOutgoingNotification(app, title, text))
+}
+
+func (n *notificationPlugin) get(id string) (internal.GonnectNotification, error) {
+	n.Lock()
+	defer n.Unlock()
+
+	notification, ok := n.notifications[id]
+	if !ok {
+		return notification, fmt.Errorf("no notification with id %q", id)
+	}
+	return notification, nil
+}
+
+func getNotificationPlugin(device string) (*Connection, *notificationPlugin, error) {
+	c, err := GetConnection(device)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, c.ctx.Value(internal.GonnectNotificationType).(*notificationPlugin), nil
+}
+
+// Reply to a notification on a device, such as a chat message
+func ReplyToNotification(device string, id string, message string) error {
+	c, n, err := getNotificationPlugin(device)
+	if err != nil {
+		return err
+	}
+
+	notification, err := n.get(id)
+	if err != nil {
+		return err
+	}
+	if notification.RequestReplyId == "" {
+		return fmt.Errorf("notification %q can not be replied to", id)
+	}
+
+	return c.Send(internal.NewGonnectPacket(internal.GonnectNotificationReply{
+		RequestReplyId: notification.RequestReplyId,
+		Message:        message,
+	}))
+}
+
+// Press one of the buttons of a notification on a device
+func ActOnNotification(device string, id string, action string) error {
+	c, n, err := getNotificationPlugin(device)
+	if err != nil {
+		return err
+	}
+
+	notification, err := n.get(id)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(notification.Actions, action) {
+		return fmt.Errorf("notification %q has no action %q, available actions are %q", id, action, notification.Actions)
+	}
+
+	return c.Send(internal.NewGonnectPacket(internal.GonnectNotificationAction{Key: id, Action: action}))
+}
+
+// Dismiss a notification on a device. If the device is not connected it is
+// dismissed when it connects again
+func DismissNotification(device string, id string) error {
+	c, _, err := getNotificationPlugin(device)
+	if err != nil {
+		return dismissLoggedNotification(device, id, true)
+	}
+
+	err = c.Send(internal.NewGonnectPacket(internal.GonnectNotificationRequest{Cancel: id}))
+	if err != nil {
+		return err
+	}
+
+	// The device may not know about it anymore, so this is not an error
+	dismissLoggedNotification(device, id, false)
+	return nil
+}
+
+// Icons are cached by their hash since the same app icon is sent with every
+// notification. The hash is whatever the device says it is, so every device has
+// a cache of its own and can not replace the icons of another device
+func (n *notificationPlugin) fetchIcon(ctx context.Context, pkt internal.GonnectPacket[internal.GonnectNotification]) (string, error) {
+	// The hash ends up in a path so make sure it is only a hash
+	hash := pkt.Body.PayloadHash
+	if _, err := hex.DecodeString(hash); err != nil || hash == "" {
+		return "", fmt.Errorf("invalid payload hash %q", hash)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	dir := config.CacheHome() + "/icons/" + device
+	path := filepath.Join(dir, hash+".png")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	err := receivePayloadToFile(ctx, pkt, maxImageSize, path)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,174 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+)
+
+// A notification received from a device, kept so that it can be looked at later
+type NotificationRecord struct {
+	Id        string
+	AppName   string
+	Title     string
+	Text      string
+	Time      time.Time
+	Dismissed bool
+	// Dismissed here while the device was not connected, the device is told
+	// about it when it connects again
+	DismissPending bool
+}
+
+// How many notifications to keep per device
+const maxNotificationRecords = 500
+
+// The notification log is stored as one file per device in the data directory.
+// The lock is for the read-modify-write cycles
+var notificationLog sync.Mutex
+
+func notificationLogPath(device string) string {
+	return config.DataHome() + "/notifications/" + device + ".json"
+}
+
+func readNotificationLog(device string) ([]NotificationRecord, error) {
+	records := make([]NotificationRecord, 0)
+
+	b, err := os.ReadFile(notificationLogPath(device))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &records)
+	return records, err
+}
+
+func writeNotificationLog(device string, records []NotificationRecord) error {
+	if len(records) > maxNotificationRecords {
+		records = records[len(records)-maxNotificationRecords:]
+	}
+
+	b, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(config.DataHome()+"/notifications", 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(notificationLogPath(device), b, 0600)
+}
+
+// Modify the log of a device while holding the lock
+func updateNotificationLog(device string, update func([]NotificationRecord) []NotificationRecord) error {
+	notificationLog.Lock()
+	defer notificationLog.Unlock()
+
+	records, err := readNotificationLog(device)
+	if err != nil {
+		return err
+	}
+
+	return writeNotificationLog(device, update(records))
+}
+
+// Add or update a notification in the log
+func logNotification(device string, n internal.GonnectNotification) error {
+	postTime := time.Now()
+	if ms, err := strconv.ParseInt(n.Time, 10, 64); err == nil {
+		postTime = time.UnixMilli(ms)
+	}
+
+	return updateNotificationLog(device, func(records []NotificationRecord) []NotificationRecord {
+		for i := range records {
+			if records[i].Id == n.Id && !records[i].Dismissed {
+				records[i].AppName = n.AppName
+				records[i].Title = n.Title
+				records[i].Text = n.Text
+				records[i].Time = postTime
+				return records
+			}
+		}
+
+		return append(records, NotificationRecord{
+			Id:      n.Id,
+			AppName: n.AppName,
+			Title:   n.Title,
+			Text:    n.Text,
+			Time:    postTime,
+		})
+	})
+}
+
+// Mark a notification as dismissed, pending is set if the device still needs to be told
+func dismissLoggedNotification(device string, id string, pending bool) error {
+	found := false
+	err := updateNotificationLog(device, func(records []NotificationRecord) []NotificationRecord {
+		for i := range records {
+			if records[i].Id == id && !records[i].Dismissed {
+				records[i].Dismissed = true
+				records[i].DismissPending = pending
+				found = true
+			}
+		}
+		return records
+	})
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("no active notification with id %q", id)
+	}
+	return nil
+}
+
+// Get the ids of notifications dismissed while the device was away and clear the pending flag
+func takePendingDismissals(device string) ([]string, error) {
+	ids := make([]string, 0)
+	err := updateNotificationLog(device, func(records []NotificationRecord) []NotificationRecord {
+		for i := range records {
+			if records[i].DismissPending {
+				ids = append(ids, records[i].Id)
+				records[i].DismissPending = false
+			}
+		}
+		return records
+	})
+
+	return ids, err
+}
+
+// Get the logged notifications of a device, oldest first. If app is not empty
+// only the notifications of that app are returned
+func GetNotifications(device string, app string) ([]NotificationRecord, error) {
+	notificationLog.Lock()
+	records, err := readNotificationLog(device)
+	notificationLog.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if app == "" {
+		return records, nil
+	}
+
+	filtered := make([]NotificationRecord, 0)
+	for _, r := range records {
+		if strings.EqualFold(r.AppName, app) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"context"
+	"sync"
+
+	"github.com/blennster/gonnect/internal/desktop"
+)
+
+// A desktop notification that was shown or closed
+type mirrorEvent struct {
+	notification desktop.Notification
+	// The id of a closed notification, 0 when one was shown
+	closed uint32
+}
+
+type mirrorSubscriber struct {
+	events chan mirrorEvent
+	done   chan struct{}
+}
+
+// Watching the desktop notifications needs a monitor connection to the session
+// bus, so one watcher is shared by the mirrors of all connected devices
+var mirror = struct {
+	subscribers map[*mirrorSubscriber]struct{}
+	// Stops the watcher when the last device is gone
+	cancel context.CancelFunc
+	sync.Mutex
+}{subscribers: make(map[*mirrorSubscriber]struct{})}
+
+// Get the desktop notifications until ctx is done. The watcher is started for
+// the first device and stopped when no device is left
+func subscribeMirror(ctx context.Context) (<-chan mirrorEvent, error) {
+	mirror.Lock()
+	defer mirror.Unlock()
+
+	if len(mirror.subscribers) == 0 {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		notifications, err := desktop.WatchNotifications(watchCtx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		closed, err := desktop.WatchClosed(watchCtx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		mirror.cancel = cancel
+		go fanOutMirror(notifications, closed)
+	}
+
+	sub := &mirrorSubscriber{events: make(chan mirrorEvent), done: make(chan struct{})}
+	mirror.subscribers[sub] = struct{}{}
+
+	context.AfterFunc(ctx, func() {
+		mirror.Lock()
+		defer mirror.Unlock()
+
+		close(sub.done)
+		delete(mirror.subscribers, sub)
+		if len(mirror.subscribers) == 0 {
+			mirror.cancel()
+		}
+	})
+
+	return sub.events, nil
+}
+
+// Send every notification to every subscribed device
+func fanOutMirror(notifications <-chan desktop.Notification, closed <-chan uint32) {
+	for {
+		var event mirrorEvent
+		select {
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			event.notification = n
+		case id, ok := <-closed:
+			if !ok {
+				return
+			}
+			event.closed = id
+		}
+
+		mirror.Lock()
+		subscribers := make([]*mirrorSubscriber, 0, len(mirror.subscribers))
+		for sub := range mirror.subscribers {
+			subscribers = append(subscribers, sub)
+		}
+		mirror.Unlock()
+
+		for _, sub := range subscribers {
+			select {
+			case sub.events <- event:
+			case <-sub.done:
+			}
+		}
+	}
+}
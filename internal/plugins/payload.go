@@ -0,0 +1,73 @@
+package plugins
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/payload"
+)
+
+// Images such as notification icons and album art are refused above this size
+const maxImageSize = 8 << 20
+
+// Fetch the payload attached to pkt from the device on the other end of the
+// connection. Payloads larger than limit are refused
+func receivePayload[T any](ctx context.Context, pkt internal.GonnectPacket[T], limit int64, w io.Writer, progress payload.Progress) error {
+	c := connectionFromContext(ctx)
+
+	host, _, err := net.SplitHostPort(c.Addr.String())
+	if err != nil {
+		return err
+	}
+
+	return payload.Receive(ctx, c.Identity.DeviceId, host, pkt.PayloadTransferInfo, pkt.PayloadSize, limit, w, progress)
+}
+
+// Fetch the payload attached to pkt into the file at path. It is written to a
+// temporary file first so that a failed transfer does not leave a broken file
+func receivePayloadToFile[T any](ctx context.Context, pkt internal.GonnectPacket[T], limit int64, path string) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = receivePayload(ctx, pkt, limit, f, nil)
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// Send pkt with size bytes from r attached as its payload and wait until the
+// device has fetched it
+func sendWithPayload[T any](ctx context.Context, pkt internal.GonnectPacket[T], r io.Reader, size int64, progress payload.Progress) error {
+	c := connectionFromContext(ctx)
+
+	sender, err := payload.Listen(c.Identity.DeviceId)
+	if err != nil {
+		return err
+	}
+
+	pkt.PayloadSize = size
+	pkt.PayloadTransferInfo = sender.TransferInfo()
+	err = c.Send(pkt)
+	if err != nil {
+		sender.Close()
+		return err
+	}
+
+	return sender.Serve(ctx, r, size, progress)
+}
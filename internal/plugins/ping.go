@@ -3,15 +3,18 @@ package plugins
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/desktop"
+	"github.com/blennster/gonnect/internal/events"
 )
 
-// Respond to ping messages from other device and ping the other device
+// Show pings from the other device and ping the other device
 type pingPlugin struct{}
 
-var message string = "pong"
-
 func (pingPlugin) React(ctx context.Context, data []byte) any {
 	var packet internal.GonnectPacket[internal.GonnectPing]
 	err := json.Unmarshal(data, &packet)
@@ -19,7 +22,63 @@ func (pingPlugin) React(ctx context.Context, data []byte) any {
 		panic(err)
 	}
 
-	pkt := internal.NewGonnectPacket(internal.GonnectPing{Message: &message})
+	c := connectionFromContext(ctx)
+	message := "Ping!"
+	if packet.Body.Message != nil && *packet.Body.Message != "" {
+		message = *packet.Body.Message
+	}
+
+	events.Publish(c.Identity.DeviceId, "ping", message)
+	_, err = desktop.Notify(desktop.Notification{
+		AppName: "gonnect",
+		Title:   c.Identity.DeviceName,
+		Body:    message,
+	})
+	if err != nil {
+		slog.Warn("failed to show ping", "error", err)
+	}
 
-	return pkt
+	return nil
 }
+
+// Send a ping to a device, it is shown with the message if it is not empty
+func Ping(device string, message string) error {
+	_, _, err := sendPing(device, message)
+	return err
+}
+
+// Send a ping and return the connection it was sent over together with a
+// channel that is closed once the ping is written to it
+func sendPing(device string, message string) (*Connection, <-chan struct{}, error) {
+	c, err := GetConnection(device)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !c.Supports(internal.GonnectPingType) {
+		return nil, nil, fmt.Errorf("device %q does not accept pings", device)
+	}
+
+	var body internal.GonnectPing
+	if message != "" {
+		body.Message = &message
+	}
+	written, err := c.send(internal.NewGonnectPacket(body))
+	return c, written, err
+}
+
+// The latency of a connection, measured with a ping
+type Latency struct {
+	// Time from writing the ping until the kernel of the device acknowledged
+	// it. The devices do not answer pings so this is not a full round trip
+	// through gonnect on the device
+	Acked time.Duration
+	// The tcp round trip time and its variation as smoothed by the kernel
+	Rtt    time.Duration
+	RttVar time.Duration
+	// Packets sent again over the lifetime of the connection, a growing
+	// number means that packets are lost
+	Retransmits uint32
+}
+
+// How long to wait for a ping to be acknowledged
+const latencyTimeout = 5 * time.Second
@@ -0,0 +1,95 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Bounds for how often the connection is checked for the acknowledgement of
+// a ping, the interval grows while the ping is not acknowledged
+const (
+	minLatencyPoll = 100 * time.Microsecond
+	maxLatencyPoll = 10 * time.Millisecond
+)
+
+// Ping a device and measure how long it takes for the ping to be
+// acknowledged. Once the ping is written everything sent so far is noted and
+// the ping is acknowledged when the device has acknowledged all of it
+func MeasureLatency(device string) (Latency, error) {
+	c, written, err := sendPing(device, "")
+	if err != nil {
+		return Latency{}, err
+	}
+
+	timeout := time.NewTimer(latencyTimeout)
+	defer timeout.Stop()
+	select {
+	case <-written:
+	case <-c.done:
+		return Latency{}, fmt.Errorf("device %q disconnected", device)
+	case <-timeout.C:
+		return Latency{}, fmt.Errorf("ping was not written within %s", latencyTimeout)
+	}
+	start := time.Now()
+
+	info, err := tcpInfo(c.netConn)
+	if err != nil {
+		return Latency{}, err
+	}
+	// Bytes sent includes retransmissions while bytes acked does not, and the
+	// ping may still be queued in the socket
+	target := info.Bytes_sent - info.Bytes_retrans + uint64(info.Notsent_bytes)
+
+	// The ack is not expected before the smoothed round trip time
+	wait := time.Duration(info.Rtt-min(info.Rtt, info.Rttvar)) * time.Microsecond
+	poll := minLatencyPoll
+	for info.Bytes_acked < target {
+		select {
+		case <-time.After(max(wait, poll)):
+		case <-c.done:
+			return Latency{}, fmt.Errorf("device %q disconnected", device)
+		case <-timeout.C:
+			return Latency{}, fmt.Errorf("ping was not acknowledged within %s", latencyTimeout)
+		}
+		wait = 0
+		poll = min(2*poll, maxLatencyPoll)
+
+		info, err = tcpInfo(c.netConn)
+		if err != nil {
+			return Latency{}, err
+		}
+	}
+
+	return Latency{
+		Acked:       time.Since(start),
+		Rtt:         time.Duration(info.Rtt) * time.Microsecond,
+		RttVar:      time.Duration(info.Rttvar) * time.Microsecond,
+		Retransmits: info.Total_retrans,
+	}, nil
+}
+
+func tcpInfo(conn net.Conn) (*unix.TCPInfo, error) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil, errors.New("connection is not a socket")
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var info *unix.TCPInfo
+	var infoErr error
+	err = raw.Control(func(fd uintptr) {
+		info, infoErr = unix.GetsockoptTCPInfo(int(fd), unix.IPPROTO_TCP, unix.TCP_INFO)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, infoErr
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package plugins
+
+import "errors"
+
+// Measuring the latency needs the tcp info of the connection, which is only
+// read on linux
+func MeasureLatency(device string) (Latency, error) {
+	return Latency{}, errors.New("measuring latency is only supported on linux")
+}
@@ -2,6 +2,11 @@ package plugins
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
 
 	"github.com/blennster/gonnect/internal"
 )
@@ -13,18 +18,172 @@ type GonnectPlugin interface {
 var (
 	_ GonnectPlugin = (*pingPlugin)(nil)
 	_ GonnectPlugin = (*clipboardPlugin)(nil)
+	_ GonnectPlugin = (*sharePlugin)(nil)
+	_ GonnectPlugin = (*notificationPlugin)(nil)
+	_ GonnectPlugin = (*batteryPlugin)(nil)
+	_ GonnectPlugin = (*runCommandPlugin)(nil)
+	_ GonnectPlugin = (*mprisPlugin)(nil)
+	_ GonnectPlugin = (*mprisRemotePlugin)(nil)
+	_ GonnectPlugin = (*findMyPhonePlugin)(nil)
+	_ GonnectPlugin = (*smsPlugin)(nil)
+	_ GonnectPlugin = (*contactsPlugin)(nil)
+	_ GonnectPlugin = (*telephonyPlugin)(nil)
+	_ GonnectPlugin = (*mousepadPlugin)(nil)
+	_ GonnectPlugin = (*presenterPlugin)(nil)
+	_ GonnectPlugin = (*systemVolumePlugin)(nil)
+	_ GonnectPlugin = (*lockPlugin)(nil)
+	_ GonnectPlugin = (*remoteKeyboardPlugin)(nil)
 )
 
-type GonnectPluginMessage internal.ChanMsg
+// A message from a plugin that is written to the device
+type GonnectPluginMessage struct {
+	internal.ChanMsg
+	// Closed once the message is written to the connection, may be nil
+	written chan struct{}
+}
+
+// Signal that the message has been written to the connection
+func (m GonnectPluginMessage) Written() {
+	if m.written != nil {
+		close(m.written)
+	}
+}
+
+type connctxkey string
+
+const connkey = connctxkey("connection")
+
+// A trusted connection to a device with its plugins loaded
+type Connection struct {
+	Identity internal.GonnectIdentity
+	Addr     net.Addr
+	// The underlying socket, used to measure the latency
+	netConn net.Conn
+
+	// The context holding the plugins for this connection, only set once all
+	// plugins are created
+	ctx  context.Context
+	done <-chan struct{}
+	ch   chan<- GonnectPluginMessage
+}
 
-func WithPlugins(ctx context.Context) (c context.Context, pluginCh <-chan GonnectPluginMessage) {
+// Send a packet to the device over the connection
+func (c *Connection) Send(pkt any) error {
+	_, err := c.send(pkt)
+	return err
+}
+
+// Send a packet to the device, the returned channel is closed once the packet
+// is written to the connection
+func (c *Connection) send(pkt any) (<-chan struct{}, error) {
+	data, err := json.Marshal(pkt)
+	if err != nil {
+		return nil, err
+	}
+
+	written := make(chan struct{})
+	select {
+	case <-c.done:
+		return nil, fmt.Errorf("device %q disconnected", c.Identity.DeviceId)
+	case c.ch <- GonnectPluginMessage{ChanMsg: internal.ChanMsg{Msg: data}, written: written}:
+		return written, nil
+	}
+}
+
+// Check if the device has said that it can receive the packet type
+func (c *Connection) Supports(t internal.GonnectMessageType) bool {
+	return slices.Contains(c.Identity.IncomingCapabilities, string(t))
+}
+
+func connectionFromContext(ctx context.Context) *Connection {
+	return ctx.Value(connkey).(*Connection)
+}
+
+// Connections that currently have plugins loaded, keyed by device id
+var connections = struct {
+	m map[string]*Connection
+	sync.RWMutex
+}{m: make(map[string]*Connection)}
+
+// Get the active connection to a device
+func GetConnection(device string) (*Connection, error) {
+	connections.RLock()
+	defer connections.RUnlock()
+
+	c, ok := connections.m[device]
+	if !ok {
+		return nil, fmt.Errorf("device %q is not connected", device)
+	}
+	return c, nil
+}
+
+// Get the active connections to all devices
+func getConnections() []*Connection {
+	connections.RLock()
+	defer connections.RUnlock()
+
+	all := make([]*Connection, 0, len(connections.m))
+	for _, c := range connections.m {
+		all = append(all, c)
+	}
+	return all
+}
+
+// Load the plugins for a connection, netConn is the socket below the tls connection
+func WithPlugins(ctx context.Context, identity internal.GonnectIdentity, netConn net.Conn) (c context.Context, pluginCh <-chan GonnectPluginMessage) {
 	ch := make(chan GonnectPluginMessage, 5)
 
-	// ping plugin is stateless and non-bidirectional as of now
+	conn := &Connection{Identity: identity, Addr: netConn.RemoteAddr(), netConn: netConn, done: ctx.Done(), ch: ch}
+	ctx = context.WithValue(ctx, connkey, conn)
+
+	// ping plugin is stateless
 	ctx = context.WithValue(ctx, internal.GonnectPingType, pingPlugin{})
 
 	cp := NewClipboardPlugin(ctx, ch)
 	ctx = context.WithValue(ctx, internal.GonnectClipboardType, cp)
 
+	ctx = context.WithValue(ctx, internal.GonnectShareRequestType, &sharePlugin{})
+	ctx = context.WithValue(ctx, internal.GonnectNotificationType, NewNotificationPlugin(ctx))
+	ctx = context.WithValue(ctx, internal.GonnectBatteryType, NewBatteryPlugin(ctx))
+	ctx = context.WithValue(ctx, internal.GonnectRunCommandRequestType, &runCommandPlugin{})
+	ctx = context.WithValue(ctx, internal.GonnectMprisRequestType, NewMprisPlugin(ctx))
+	ctx = context.WithValue(ctx, internal.GonnectMprisType, NewMprisRemotePlugin(ctx))
+	ctx = context.WithValue(ctx, internal.GonnectFindMyPhoneType, &findMyPhonePlugin{})
+	ctx = context.WithValue(ctx, internal.GonnectSmsMessagesType, NewSmsPlugin())
+	ctx = context.WithValue(ctx, internal.GonnectTelephonyType, NewTelephonyPlugin(ctx))
+	ctx = context.WithValue(ctx, internal.GonnectMousepadRequestType, NewMousepadPlugin(ctx))
+
+	// The echo and keyboard state come from the remote keyboard of the device
+	keyboard := NewRemoteKeyboardPlugin()
+	ctx = context.WithValue(ctx, internal.GonnectMousepadEchoType, keyboard)
+	ctx = context.WithValue(ctx, internal.GonnectMousepadKeyboardStateType, keyboard)
+	ctx = context.WithValue(ctx, internal.GonnectPresenterType, NewPresenterPlugin(ctx))
+	ctx = context.WithValue(ctx, internal.GonnectSystemVolumeRequestType, NewSystemVolumePlugin(ctx))
+
+	// The lock state and lock requests are handled by the same plugin
+	lock := NewLockPlugin(ctx)
+	ctx = context.WithValue(ctx, internal.GonnectLockType, lock)
+	ctx = context.WithValue(ctx, internal.GonnectLockRequestType, lock)
+
+	// Both contacts responses are handled by the same plugin
+	contacts := NewContactsPlugin(ctx)
+	ctx = context.WithValue(ctx, internal.GonnectContactsResponseUidsType, contacts)
+	ctx = context.WithValue(ctx, internal.GonnectContactsResponseVcardsType, contacts)
+
+	conn.ctx = ctx
+	connections.Lock()
+	connections.m[identity.DeviceId] = conn
+	connections.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		connections.Lock()
+		defer connections.Unlock()
+		// The device may already have reconnected
+		if connections.m[identity.DeviceId] == conn {
+			delete(connections.m, identity.DeviceId)
+		}
+	}()
+
 	return ctx, ch
 }
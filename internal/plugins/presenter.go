@@ -0,0 +1,108 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/desktop"
+	"github.com/blennster/gonnect/internal/input"
+	"github.com/blennster/gonnect/internal/security"
+)
+
+// The presenter plugin moves the pointer with a presentation remote and keeps
+// the screensaver away while presenting. The slide keys are handled by the
+// mousepad plugin, so both need the remote input permission
+type presenterPlugin struct {
+	presenting bool
+	// Cookie of the screensaver inhibition, 0 if it could not be inhibited
+	cookie uint32
+	sync.Mutex
+}
+
+// Pixels moved for a motion of half the screen, since the size of the screen
+// is not known to the input backends
+const presenterScale = 1000
+
+// Create a new presenter plugin, presentation mode ends when the device disconnects
+func NewPresenterPlugin(ctx context.Context) *presenterPlugin {
+	p := &presenterPlugin{}
+	context.AfterFunc(ctx, p.stop)
+
+	return p
+}
+
+// React implements GonnectPlugin.
+func (p *presenterPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectPresenter]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	if !security.Devices.HasPermission(device, security.PermissionRemoteInput) {
+		slog.Warn("device is not allowed to send input", "device", device)
+		return nil
+	}
+
+	if pkt.Body.Stop {
+		p.stop()
+		return nil
+	}
+
+	p.start(device)
+
+	backend, err := input.Get()
+	if err != nil {
+		slog.Error("failed to get input backend", "error", err)
+		return nil
+	}
+	err = backend.Move(int(pkt.Body.Dx*presenterScale), int(pkt.Body.Dy*presenterScale))
+	if err != nil {
+		slog.Error("failed to move pointer", "error", err)
+	}
+
+	return nil
+}
+
+// Enter presentation mode if not already presenting
+func (p *presenterPlugin) start(device string) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.presenting {
+		return
+	}
+	p.presenting = true
+	slog.Info("presentation started", "device", device)
+
+	cookie, err := desktop.InhibitScreenSaver("gonnect", "Presenting with "+device)
+	if err != nil {
+		slog.Warn("failed to inhibit screensaver", "error", err)
+		return
+	}
+	p.cookie = cookie
+}
+
+func (p *presenterPlugin) stop() {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.presenting {
+		return
+	}
+	p.presenting = false
+	slog.Info("presentation stopped")
+
+	if p.cookie == 0 {
+		return
+	}
+	err := desktop.UnInhibitScreenSaver(p.cookie)
+	if err != nil {
+		slog.Warn("failed to allow screensaver", "error", err)
+	}
+	p.cookie = 0
+}
@@ -0,0 +1,282 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/events"
+)
+
+// How long to wait for the device to acknowledge typed keys
+const keyAckTimeout = 2 * time.Second
+
+// The remote keyboard plugin types on another device. The device only accepts
+// the keys while its own remote keyboard is the active keyboard
+type remoteKeyboardPlugin struct {
+	// The device has told if its keyboard is active
+	known  bool
+	active bool
+	// Keys acknowledged while typing, acked is signalled on every ack
+	acks  atomic.Int64
+	acked chan struct{}
+	// Only one text is typed at a time so that the acks can be counted
+	typing sync.Mutex
+	sync.Mutex
+}
+
+// What is known about the keyboard of a device after typing on it
+type KeyboardStatus struct {
+	Known  bool
+	Active bool
+	// Keys sent and acknowledged by the device
+	Sent  int
+	Acked int
+}
+
+var mousepadKeyNames = map[string]int{
+	"backspace":  internal.MousepadKeyBackspace,
+	"tab":        internal.MousepadKeyTab,
+	"linefeed":   internal.MousepadKeyLinefeed,
+	"left":       internal.MousepadKeyLeft,
+	"up":         internal.MousepadKeyUp,
+	"right":      internal.MousepadKeyRight,
+	"down":       internal.MousepadKeyDown,
+	"pageup":     internal.MousepadKeyPageUp,
+	"pagedown":   internal.MousepadKeyPageDown,
+	"home":       internal.MousepadKeyHome,
+	"end":        internal.MousepadKeyEnd,
+	"enter":      internal.MousepadKeyEnter,
+	"delete":     internal.MousepadKeyDelete,
+	"escape":     internal.MousepadKeyEscape,
+	"sysreq":     internal.MousepadKeySysReq,
+	"scrolllock": internal.MousepadKeyScrollLock,
+}
+
+func init() {
+	for i := 0; i <= internal.MousepadKeyF12-internal.MousepadKeyF1; i++ {
+		mousepadKeyNames[fmt.Sprintf("f%d", i+1)] = internal.MousepadKeyF1 + i
+	}
+}
+
+// Control characters in typed text that are sent as special keys
+var mousepadControlKeys = map[rune]int{
+	'\n':   internal.MousepadKeyEnter,
+	'\t':   internal.MousepadKeyTab,
+	'\b':   internal.MousepadKeyBackspace,
+	'\x7f': internal.MousepadKeyBackspace,
+	'\x1b': internal.MousepadKeyEscape,
+}
+
+func NewRemoteKeyboardPlugin() *remoteKeyboardPlugin {
+	return &remoteKeyboardPlugin{acked: make(chan struct{}, 1)}
+}
+
+// React implements GonnectPlugin.
+func (r *remoteKeyboardPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[any]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+
+	switch pkt.Type {
+	case internal.GonnectMousepadKeyboardStateType:
+		var pkt internal.GonnectPacket[internal.GonnectMousepadKeyboardState]
+		err := json.Unmarshal(data, &pkt)
+		if err != nil {
+			panic(err)
+		}
+
+		r.setActive(pkt.Body.State)
+		state := "inactive"
+		if pkt.Body.State {
+			state = "active"
+		}
+		events.Publish(device, "keyboard", "remote keyboard "+state)
+	case internal.GonnectMousepadEchoType:
+		var pkt internal.GonnectPacket[internal.GonnectMousepadEcho]
+		err := json.Unmarshal(data, &pkt)
+		if err != nil {
+			panic(err)
+		}
+		if !pkt.Body.IsAck {
+			return nil
+		}
+
+		// Keys are only acknowledged by an active keyboard
+		r.setActive(true)
+		r.acks.Add(1)
+		// A signal that is already pending wakes the waiter just as well
+		select {
+		case r.acked <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (r *remoteKeyboardPlugin) setActive(active bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.known = true
+	r.active = active
+}
+
+// Send the keys and wait for them to be acknowledged
+func (r *remoteKeyboardPlugin) send(c *Connection, reqs []internal.GonnectMousepadRequest) (KeyboardStatus, error) {
+	r.typing.Lock()
+	defer r.typing.Unlock()
+
+	// Late acks from earlier keys should not be counted
+	r.acks.Store(0)
+
+	var status KeyboardStatus
+	for _, req := range reqs {
+		req.SendAck = true
+		err := c.Send(internal.NewGonnectPacket(req))
+		if err != nil {
+			return status, err
+		}
+		status.Sent++
+	}
+
+	status.Acked = r.waitForAcks(status.Sent)
+
+	r.Lock()
+	status.Known = r.known
+	status.Active = r.active
+	r.Unlock()
+	return status, nil
+}
+
+// Count the acks until all keys are acknowledged or no ack has come for a while
+func (r *remoteKeyboardPlugin) waitForAcks(sent int) int {
+	for {
+		acked := int(r.acks.Load())
+		if acked >= sent {
+			return sent
+		}
+
+		select {
+		case <-r.acked:
+		case <-time.After(keyAckTimeout):
+			return acked
+		}
+	}
+}
+
+// Split text into runs of characters and special keys for control characters
+func keyRequests(text string) []internal.GonnectMousepadRequest {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var reqs []internal.GonnectMousepadRequest
+	var run strings.Builder
+	flush := func() {
+		if run.Len() > 0 {
+			reqs = append(reqs, internal.GonnectMousepadRequest{Key: run.String()})
+			run.Reset()
+		}
+	}
+
+	for _, r := range text {
+		if key, ok := mousepadControlKeys[r]; ok {
+			flush()
+			reqs = append(reqs, internal.GonnectMousepadRequest{SpecialKey: key})
+			continue
+		}
+		if r == '\r' || unicode.IsControl(r) {
+			continue
+		}
+		run.WriteRune(r)
+	}
+	flush()
+
+	return reqs
+}
+
+// Parse a key such as "enter", "f5" or "ctrl+shift+a"
+func parseKey(spec string) (internal.GonnectMousepadRequest, error) {
+	var req internal.GonnectMousepadRequest
+
+	// The key itself may be a plus, as in "ctrl++"
+	key := spec
+	var mods []string
+	if i := strings.LastIndex(strings.TrimSuffix(spec, "+"), "+"); i >= 0 {
+		key = spec[i+1:]
+		mods = strings.Split(spec[:i], "+")
+	}
+
+	for _, mod := range mods {
+		switch strings.ToLower(mod) {
+		case "shift":
+			req.Shift = true
+		case "ctrl":
+			req.Ctrl = true
+		case "alt":
+			req.Alt = true
+		case "super":
+			req.Super = true
+		default:
+			return req, fmt.Errorf("unknown modifier %q", mod)
+		}
+	}
+
+	if special, ok := mousepadKeyNames[strings.ToLower(key)]; ok {
+		req.SpecialKey = special
+	} else if len([]rune(key)) == 1 {
+		req.Key = key
+	} else {
+		return req, fmt.Errorf("unknown key %q", key)
+	}
+
+	return req, nil
+}
+
+func remoteKeyboard(device string) (*Connection, *remoteKeyboardPlugin, error) {
+	c, err := GetConnection(device)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !c.Supports(internal.GonnectMousepadRequestType) {
+		return nil, nil, fmt.Errorf("device %q has no remote keyboard", device)
+	}
+
+	return c, c.ctx.Value(internal.GonnectMousepadEchoType).(*remoteKeyboardPlugin), nil
+}
+
+// Type text on a device, newlines, tabs and other control characters are sent
+// as special keys
+func TypeText(device string, text string) (KeyboardStatus, error) {
+	c, r, err := remoteKeyboard(device)
+	if err != nil {
+		return KeyboardStatus{}, err
+	}
+
+	return r.send(c, keyRequests(text))
+}
+
+// Press a key on a device, see parseKey for the format
+func PressKey(device string, key string) (KeyboardStatus, error) {
+	req, err := parseKey(key)
+	if err != nil {
+		return KeyboardStatus{}, err
+	}
+
+	c, r, err := remoteKeyboard(device)
+	if err != nil {
+		return KeyboardStatus{}, err
+	}
+
+	return r.send(c, []internal.GonnectMousepadRequest{req})
+}
@@ -0,0 +1,90 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blennster/gonnect/internal"
+)
+
+func TestKeyRequests(t *testing.T) {
+	tests := []struct {
+		text string
+		reqs []internal.GonnectMousepadRequest
+	}{
+		{"", nil},
+		{"héllo wörld", []internal.GonnectMousepadRequest{{Key: "héllo wörld"}}},
+		{"user\tpass\r\n", []internal.GonnectMousepadRequest{
+			{Key: "user"},
+			{SpecialKey: internal.MousepadKeyTab},
+			{Key: "pass"},
+			{SpecialKey: internal.MousepadKeyEnter},
+		}},
+		{"ab\x7f\x1b", []internal.GonnectMousepadRequest{
+			{Key: "ab"},
+			{SpecialKey: internal.MousepadKeyBackspace},
+			{SpecialKey: internal.MousepadKeyEscape},
+		}},
+		// Other control characters are dropped
+		{"a\x01b\rc", []internal.GonnectMousepadRequest{{Key: "abc"}}},
+	}
+
+	for _, test := range tests {
+		reqs := keyRequests(test.text)
+		if !reflect.DeepEqual(reqs, test.reqs) {
+			t.Errorf("keyRequests(%q) = %+v, want %+v", test.text, reqs, test.reqs)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		spec string
+		req  internal.GonnectMousepadRequest
+	}{
+		{"enter", internal.GonnectMousepadRequest{SpecialKey: internal.MousepadKeyEnter}},
+		{"F5", internal.GonnectMousepadRequest{SpecialKey: internal.MousepadKeyF1 + 4}},
+		{"f12", internal.GonnectMousepadRequest{SpecialKey: internal.MousepadKeyF12}},
+		{"a", internal.GonnectMousepadRequest{Key: "a"}},
+		{"+", internal.GonnectMousepadRequest{Key: "+"}},
+		{"ctrl++", internal.GonnectMousepadRequest{Key: "+", Ctrl: true}},
+		{"ctrl+shift+a", internal.GonnectMousepadRequest{Key: "a", Ctrl: true, Shift: true}},
+		{"Alt+Super+left", internal.GonnectMousepadRequest{SpecialKey: internal.MousepadKeyLeft, Alt: true, Super: true}},
+	}
+
+	for _, test := range tests {
+		req, err := parseKey(test.spec)
+		if err != nil {
+			t.Errorf("parseKey(%q) failed: %s", test.spec, err)
+			continue
+		}
+		if req != test.req {
+			t.Errorf("parseKey(%q) = %+v, want %+v", test.spec, req, test.req)
+		}
+	}
+
+	for _, spec := range []string{"", "foo", "hyper+a", "ctrl+", "a+"} {
+		_, err := parseKey(spec)
+		if err == nil {
+			t.Errorf("parseKey(%q) did not fail", spec)
+		}
+	}
+}
+
+func TestWaitForAcks(t *testing.T) {
+	r := NewRemoteKeyboardPlugin()
+
+	// More acks than a buffered channel would have held
+	for i := 0; i < 200; i++ {
+		r.acks.Add(1)
+		select {
+		case r.acked <- struct{}{}:
+		default:
+		}
+	}
+
+	acked := r.waitForAcks(200)
+	if acked != 200 {
+		t.Errorf("got %d acks, want 200", acked)
+	}
+}
@@ -23,6 +23,42 @@ func Handle(ctx context.Context, data []byte) []byte {
 		t = ctx.Value(internal.GonnectPingType)
 	case internal.GonnectClipboardType, internal.GonnectClipboardConnectType:
 		t = ctx.Value(internal.GonnectClipboardType)
+	case internal.GonnectShareRequestType:
+		t = ctx.Value(internal.GonnectShareRequestType)
+	case internal.GonnectNotificationType:
+		t = ctx.Value(internal.GonnectNotificationType)
+	case internal.GonnectBatteryType:
+		t = ctx.Value(internal.GonnectBatteryType)
+	case internal.GonnectRunCommandRequestType:
+		t = ctx.Value(internal.GonnectRunCommandRequestType)
+	case internal.GonnectMprisRequestType:
+		t = ctx.Value(internal.GonnectMprisRequestType)
+	case internal.GonnectMprisType:
+		t = ctx.Value(internal.GonnectMprisType)
+	case internal.GonnectFindMyPhoneType:
+		t = ctx.Value(internal.GonnectFindMyPhoneType)
+	case internal.GonnectSmsMessagesType:
+		t = ctx.Value(internal.GonnectSmsMessagesType)
+	case internal.GonnectMousepadRequestType:
+		t = ctx.Value(internal.GonnectMousepadRequestType)
+	case internal.GonnectMousepadEchoType:
+		t = ctx.Value(internal.GonnectMousepadEchoType)
+	case internal.GonnectMousepadKeyboardStateType:
+		t = ctx.Value(internal.GonnectMousepadKeyboardStateType)
+	case internal.GonnectPresenterType:
+		t = ctx.Value(internal.GonnectPresenterType)
+	case internal.GonnectSystemVolumeRequestType:
+		t = ctx.Value(internal.GonnectSystemVolumeRequestType)
+	case internal.GonnectLockType:
+		t = ctx.Value(internal.GonnectLockType)
+	case internal.GonnectLockRequestType:
+		t = ctx.Value(internal.GonnectLockRequestType)
+	case internal.GonnectTelephonyType:
+		t = ctx.Value(internal.GonnectTelephonyType)
+	case internal.GonnectContactsResponseUidsType:
+		t = ctx.Value(internal.GonnectContactsResponseUidsType)
+	case internal.GonnectContactsResponseVcardsType:
+		t = ctx.Value(internal.GonnectContactsResponseVcardsType)
 	default:
 		slog.Error("unknown packet type in plugin handler", "type", packet.Type)
 		return nil
@@ -35,6 +71,10 @@ func Handle(ctx context.Context, data []byte) []byte {
 	}
 
 	pkt := plugin.React(ctx, data)
+	// Not all packets warrant a response
+	if pkt == nil {
+		return nil
+	}
 	response, err := json.Marshal(pkt)
 	if err != nil {
 		slog.Error("error marshalling response from plugin", "plugin", plugin, "err", err)
@@ -0,0 +1,147 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/events"
+	"github.com/blennster/gonnect/internal/security"
+)
+
+// The run command plugin lets the other device run commands configured in
+// commands.json, if the device has been given permission to do so
+type runCommandPlugin struct{}
+
+// React implements GonnectPlugin.
+func (r *runCommandPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectRunCommandRequest]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	allowed := security.Devices.HasPermission(device, security.PermissionRunCommand)
+
+	switch {
+	case pkt.Body.RequestCommandList:
+		list, err := commandList(device)
+		if err != nil {
+			slog.Error("failed to read commands", "error", err)
+			return nil
+		}
+		return list
+	case pkt.Body.Key != "":
+		if !allowed {
+			slog.Warn("device is not allowed to run commands", "device", device, "key", pkt.Body.Key)
+			return nil
+		}
+
+		go r.run(ctx, pkt.Body.Key)
+	case pkt.Body.Setup:
+		slog.Info("commands are configured with the cli", "device", device)
+	}
+
+	return nil
+}
+
+// The commands a device can run, none if it is not allowed to run commands
+func commandList(device string) (internal.GonnectPacket[internal.GonnectRunCommand], error) {
+	commands := make(map[string]config.Command)
+	// Do not tell the device about anything it can not run
+	if security.Devices.HasPermission(device, security.PermissionRunCommand) {
+		var err error
+		commands, err = config.GetCommands()
+		if err != nil {
+			return internal.GonnectPacket[internal.GonnectRunCommand]{}, err
+		}
+	}
+
+	list, err := json.Marshal(commands)
+	if err != nil {
+		panic(err)
+	}
+	return internal.NewGonnectPacket(internal.GonnectRunCommand{CommandList: string(list)}), nil
+}
+
+// Send the command list to a connected device so that it does not show a
+// stale list after the commands or its permission changed
+func SendCommandList(device string) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectRunCommandType) {
+		return nil
+	}
+
+	list, err := commandList(device)
+	if err != nil {
+		return err
+	}
+	return c.Send(list)
+}
+
+// Send the command list to every connected device that may run commands
+func SendCommandLists() {
+	for _, c := range getConnections() {
+		if !security.Devices.HasPermission(c.Identity.DeviceId, security.PermissionRunCommand) {
+			continue
+		}
+
+		err := SendCommandList(c.Identity.DeviceId)
+		if err != nil {
+			slog.Error("failed to send command list", "device", c.Identity.DeviceId, "error", err)
+		}
+	}
+}
+
+func (r *runCommandPlugin) run(ctx context.Context, key string) {
+	device := connectionFromContext(ctx).Identity.DeviceId
+
+	commands, err := config.GetCommands()
+	if err != nil {
+		slog.Error("failed to read commands", "error", err)
+		return
+	}
+	command, ok := commands[key]
+	if !ok {
+		slog.Warn("device tried to run unknown command", "device", device, "key", key)
+		return
+	}
+
+	// A started command keeps running if the device disconnects
+	ctx, cancel := context.WithTimeout(context.Background(), config.GetSettings().GetCommandTimeout())
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command.Command)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	slog.Info("running command", "device", device, "name", command.Name, "command", command.Command)
+	err = cmd.Run()
+
+	exitCode := cmd.ProcessState.ExitCode()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		slog.Error("failed to run command", "device", device, "name", command.Name, "error", err)
+		events.Publish(device, "runcommand", fmt.Sprintf("failed to run %q: %s", command.Name, err))
+		return
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		slog.Warn("command timed out", "device", device, "name", command.Name, "output", output.String())
+		events.Publish(device, "runcommand", fmt.Sprintf("%q timed out", command.Name))
+		return
+	}
+
+	slog.Info("command finished", "device", device, "name", command.Name, "exitCode", exitCode, "output", output.String())
+	events.Publish(device, "runcommand", fmt.Sprintf("%q exited with status %d", command.Name, exitCode))
+}
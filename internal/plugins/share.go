@@ -0,0 +1,330 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/events"
+	"github.com/blennster/gonnect/internal/payload"
+	"github.com/google/uuid"
+)
+
+// The share plugin sends and receives files to and from the other device,
+// shared urls are opened and shared text is put on the clipboard
+type sharePlugin struct{}
+
+// The state of a file being sent, as reported to the cli
+type TransferStatus struct {
+	Id          string
+	Device      string
+	Path        string
+	Size        int64
+	Transferred int64
+	Done        bool
+	// Set if the transfer failed
+	Err string
+}
+
+type transfer struct {
+	status TransferStatus
+	sync.Mutex
+}
+
+func (t *transfer) progress(transferred int64) {
+	t.Lock()
+	defer t.Unlock()
+	t.status.Transferred = transferred
+}
+
+func (t *transfer) finish(err error) {
+	t.Lock()
+	t.status.Done = true
+	if err != nil {
+		t.status.Err = err.Error()
+	}
+	id := t.status.Id
+	t.Unlock()
+
+	time.AfterFunc(finishedTransferTTL, func() {
+		transfers.Lock()
+		delete(transfers.m, id)
+		transfers.Unlock()
+	})
+}
+
+// How long a finished transfer can still be asked for
+const finishedTransferTTL = 10 * time.Minute
+
+// Outgoing transfers, they are kept around for a while so that the cli can ask
+// for them after they are done
+var transfers = struct {
+	m map[string]*transfer
+	sync.RWMutex
+}{m: make(map[string]*transfer)}
+
+func GetTransfer(id string) (TransferStatus, error) {
+	transfers.RLock()
+	defer transfers.RUnlock()
+
+	t, ok := transfers.m[id]
+	if !ok {
+		return TransferStatus{}, fmt.Errorf("no transfer with id %q", id)
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	return t.status, nil
+}
+
+// Send files to a device one after another. The returned ids can be used with
+// GetTransfer to follow the progress
+func SendFiles(device string, paths []string) ([]string, error) {
+	c, err := GetConnection(device)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Supports(internal.GonnectShareRequestType) {
+		return nil, fmt.Errorf("device %q does not accept shared files", device)
+	}
+
+	var totalSize int64
+	queued := make([]*transfer, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%q is not a regular file", path)
+		}
+
+		totalSize += info.Size()
+		queued = append(queued, &transfer{status: TransferStatus{
+			Id:     uuid.NewString(),
+			Device: device,
+			Path:   path,
+			Size:   info.Size(),
+		}})
+	}
+
+	ids := make([]string, 0, len(queued))
+	transfers.Lock()
+	for _, t := range queued {
+		transfers.m[t.status.Id] = t
+		ids = append(ids, t.status.Id)
+	}
+	transfers.Unlock()
+
+	s := c.ctx.Value(internal.GonnectShareRequestType).(*sharePlugin)
+	go func() {
+		for _, t := range queued {
+			err := s.send(c.ctx, t, len(queued), totalSize)
+			if err != nil {
+				slog.Error("failed to send file", "device", device, "path", t.status.Path, "error", err)
+			}
+			t.finish(err)
+		}
+	}()
+
+	return ids, nil
+}
+
+// React implements GonnectPlugin.
+func (s *sharePlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectShareRequest]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+
+	switch {
+	case pkt.PayloadTransferInfo != nil:
+		// The transfer can take a while so do not block the connection
+		go func() {
+			err := s.receive(ctx, pkt)
+			if err != nil {
+				slog.Error("failed to receive file", "filename", pkt.Body.Filename, "error", err)
+				events.Publish(device, "share.file", fmt.Sprintf("failed to receive %q: %s", pkt.Body.Filename, err))
+			}
+		}()
+	case pkt.Body.Url != "":
+		err = s.openUrl(pkt.Body.Url)
+		if err != nil {
+			slog.Error("failed to open shared url", "url", pkt.Body.Url, "error", err)
+			events.Publish(device, "share.url", fmt.Sprintf("failed to open %s: %s", pkt.Body.Url, err))
+			break
+		}
+		events.Publish(device, "share.url", "opened "+pkt.Body.Url)
+	case pkt.Body.Text != "":
+		err = s.saveText(ctx, pkt.Body.Text)
+		if err != nil {
+			slog.Error("failed to save shared text", "error", err)
+			events.Publish(device, "share.text", "failed to save shared text: "+err.Error())
+		}
+	default:
+		slog.Warn("share request without any content", "data", string(data))
+	}
+
+	return nil
+}
+
+// Open an url that was shared from the other device
+func (s *sharePlugin) openUrl(shared string) error {
+	u, err := url.Parse(shared)
+	if err != nil {
+		return err
+	}
+	// Do not let the other device open local files or arbitrary handlers
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("refusing to open url with scheme %q", u.Scheme)
+	}
+
+	opener := config.GetSettings().GetUrlOpener()
+	cmd := exec.Command(opener[0], append(opener[1:], u.String())...)
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+	// The opener may be the browser itself, so do not wait for it to finish
+	go cmd.Wait()
+
+	return nil
+}
+
+// Put shared text on the clipboard or in a file depending on the settings
+func (s *sharePlugin) saveText(ctx context.Context, text string) error {
+	device := connectionFromContext(ctx).Identity.DeviceId
+
+	switch mode := config.GetSettings().SharedText; mode {
+	case "", "clipboard":
+		cp := ctx.Value(internal.GonnectClipboardType).(*clipboardPlugin)
+		err := cp.write(text)
+		if err != nil {
+			return err
+		}
+		events.Publish(device, "share.text", "copied shared text to clipboard")
+	case "file":
+		name := fmt.Sprintf("shared-text-%s.txt", time.Now().Format("20060102-150405"))
+		f, err := createUnique(config.DownloadDir(), name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = f.WriteString(text)
+		if err != nil {
+			return err
+		}
+		events.Publish(device, "share.text", "saved shared text to "+f.Name())
+	default:
+		return fmt.Errorf("unknown sharedText setting %q", mode)
+	}
+
+	return nil
+}
+
+// Fetch the payload from the other device and save it in the download directory
+func (s *sharePlugin) receive(ctx context.Context, pkt internal.GonnectPacket[internal.GonnectShareRequest]) error {
+	c := connectionFromContext(ctx)
+	dir := config.DownloadDir()
+
+	// The size is announced by the device, do not let it fill the disk
+	free, err := payload.FreeSpace(dir)
+	if err != nil {
+		return err
+	}
+	if pkt.PayloadSize > free {
+		return fmt.Errorf("not enough space for %d bytes in %s", pkt.PayloadSize, dir)
+	}
+
+	f, err := createUnique(dir, pkt.Body.Filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	slog.Info("receiving file", "device", c.Identity.DeviceId, "path", f.Name(), "size", pkt.PayloadSize)
+	err = receivePayload(ctx, pkt, free, f, nil)
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if pkt.Body.LastModified > 0 {
+		mtime := time.UnixMilli(pkt.Body.LastModified)
+		os.Chtimes(f.Name(), mtime, mtime)
+	}
+
+	slog.Info("received file", "device", c.Identity.DeviceId, "path", f.Name())
+	events.Publish(c.Identity.DeviceId, "share.file", "received "+f.Name())
+	return nil
+}
+
+// Serve a file as a payload and tell the other device to fetch it
+func (s *sharePlugin) send(ctx context.Context, t *transfer, numberOfFiles int, totalSize int64) error {
+	c := connectionFromContext(ctx)
+
+	f, err := os.Open(t.status.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	pkt := internal.NewGonnectPacket(internal.GonnectShareRequest{
+		Filename:         filepath.Base(t.status.Path),
+		LastModified:     info.ModTime().UnixMilli(),
+		NumberOfFiles:    numberOfFiles,
+		TotalPayloadSize: totalSize,
+	})
+
+	slog.Info("sending file", "device", c.Identity.DeviceId, "path", t.status.Path, "size", info.Size())
+	err = sendWithPayload(ctx, pkt, f, info.Size(), t.progress)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("sent file", "device", c.Identity.DeviceId, "path", t.status.Path)
+	return nil
+}
+
+// Create a new file in dir without overwriting existing files by appending a
+// counter to the name. The name is stripped of any path since it is untrusted
+func createUnique(dir string, name string) (*os.File, error) {
+	name = filepath.Base(name)
+	if name == "." || name == "/" || name == ".." {
+		name = "shared"
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+
+		f, err := os.OpenFile(filepath.Join(dir, candidate), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+}
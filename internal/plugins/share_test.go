@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateUnique(t *testing.T) {
+	tests := []struct {
+		name     string
+		shared   string
+		existing []string
+		want     string
+	}{
+		{
+			name:   "plain",
+			shared: "photo.jpg",
+			want:   "photo.jpg",
+		},
+		{
+			name:   "path traversal",
+			shared: "../../etc/x",
+			want:   "x",
+		},
+		{
+			name:   "empty",
+			shared: "",
+			want:   "shared",
+		},
+		{
+			name:   "parent",
+			shared: "..",
+			want:   "shared",
+		},
+		{
+			name:   "root",
+			shared: "/",
+			want:   "shared",
+		},
+		{
+			name:     "no extension",
+			shared:   "notes",
+			existing: []string{"notes"},
+			want:     "notes (1)",
+		},
+		{
+			name:     "collisions",
+			shared:   "photo.jpg",
+			existing: []string{"photo.jpg", "photo (1).jpg", "photo (2).jpg"},
+			want:     "photo (3).jpg",
+		},
+		{
+			name:     "double extension",
+			shared:   "archive.tar.gz",
+			existing: []string{"archive.tar.gz"},
+			want:     "archive.tar (1).gz",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range test.existing {
+				err := os.WriteFile(filepath.Join(dir, name), []byte("existing"), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f, err := createUnique(dir, test.shared)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			if f.Name() != filepath.Join(dir, test.want) {
+				t.Errorf("got %q, want %q", f.Name(), filepath.Join(dir, test.want))
+			}
+			// Existing files are left alone
+			for _, name := range test.existing {
+				b, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil || string(b) != "existing" {
+					t.Errorf("%q was overwritten", name)
+				}
+			}
+		})
+	}
+}
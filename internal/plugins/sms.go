@@ -0,0 +1,142 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/blennster/gonnect/internal"
+)
+
+// The sms plugin caches the text messages of the other device and sends text
+// messages through it
+type smsPlugin struct {
+	// Closed and replaced every time messages arrive, so that requests can wait for them
+	received chan struct{}
+	sync.Mutex
+}
+
+const (
+	// How long to wait for the device to answer a request
+	smsTimeout = 10 * time.Second
+	// The answer may be split over several packets, it is done when no packet
+	// has arrived for this long
+	smsSettle = time.Second
+)
+
+func NewSmsPlugin() *smsPlugin {
+	return &smsPlugin{received: make(chan struct{})}
+}
+
+// React implements GonnectPlugin.
+func (s *smsPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectSmsMessages]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	err = cacheSmsMessages(device, pkt.Body.Messages)
+	if err != nil {
+		slog.Error("failed to cache text messages", "device", device, "error", err)
+	}
+
+	s.Lock()
+	close(s.received)
+	s.received = make(chan struct{})
+	s.Unlock()
+
+	return nil
+}
+
+func (s *smsPlugin) changed() <-chan struct{} {
+	s.Lock()
+	defer s.Unlock()
+	return s.received
+}
+
+// Send a request for messages and wait until the device has answered
+func requestSms(device string, pkt any) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectSmsRequestConversationsType) {
+		return fmt.Errorf("device %q does not share its text messages", device)
+	}
+
+	s := c.ctx.Value(internal.GonnectSmsMessagesType).(*smsPlugin)
+	received := s.changed()
+	err = c.Send(pkt)
+	if err != nil {
+		return err
+	}
+
+	answered := false
+	wait := smsTimeout
+	for {
+		select {
+		case <-received:
+			answered = true
+			received = s.changed()
+			wait = smsSettle
+		case <-time.After(wait):
+			if !answered {
+				return fmt.Errorf("device %q did not send any messages", device)
+			}
+			return nil
+		case <-c.done:
+			return fmt.Errorf("device %q disconnected", device)
+		}
+	}
+}
+
+// Get the latest message of every conversation, newest first. The messages
+// are fetched from the device if it is connected, otherwise they are read
+// from the cache
+func GetConversations(device string) ([]internal.GonnectSmsMessage, error) {
+	if _, err := GetConnection(device); err == nil {
+		err := requestSms(device, internal.NewGonnectPacket(internal.GonnectSmsRequestConversations{}))
+		if err != nil {
+			slog.Warn("failed to fetch conversations, using the cache", "device", device, "error", err)
+		}
+	}
+
+	return cachedConversations(device)
+}
+
+// Get the messages of a conversation, oldest first. Like GetConversations
+// the cache is used if the device is not connected
+func GetConversation(device string, thread int64) ([]internal.GonnectSmsMessage, error) {
+	if _, err := GetConnection(device); err == nil {
+		err := requestSms(device, internal.NewGonnectPacket(internal.GonnectSmsRequestConversation{ThreadId: thread}))
+		if err != nil {
+			slog.Warn("failed to fetch conversation, using the cache", "device", device, "thread", thread, "error", err)
+		}
+	}
+
+	return cachedConversation(device, thread)
+}
+
+// Send a text message from a device
+func SendSms(device string, to string, text string) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectSmsRequestType) {
+		return fmt.Errorf("device %q can not send text messages", device)
+	}
+
+	return c.Send(internal.NewGonnectPacket(internal.GonnectSmsRequest{
+		Version:     2,
+		SendSms:     true,
+		Addresses:   []internal.GonnectSmsAddress{{Address: to}},
+		PhoneNumber: to,
+		MessageBody: text,
+	}))
+}
@@ -0,0 +1,123 @@
+package plugins
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+)
+
+// Text messages received from the devices are cached as one file per device
+// in the data directory so that they can be read while the device is away.
+// The lock is for the read-modify-write cycles
+var smsCache sync.Mutex
+
+func smsCachePath(device string) string {
+	return config.DataHome() + "/sms/" + device + ".json"
+}
+
+func readSmsCache(device string) ([]internal.GonnectSmsMessage, error) {
+	messages := make([]internal.GonnectSmsMessage, 0)
+
+	b, err := os.ReadFile(smsCachePath(device))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return messages, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, &messages)
+	return messages, err
+}
+
+// Add messages to the cache, messages already in it are replaced
+func cacheSmsMessages(device string, received []internal.GonnectSmsMessage) error {
+	smsCache.Lock()
+	defer smsCache.Unlock()
+
+	messages, err := readSmsCache(device)
+	if err != nil {
+		return err
+	}
+
+	// Sms and mms have separate ids, so both are needed to tell messages apart
+	type key struct {
+		id    int64
+		event int
+	}
+	index := make(map[key]int, len(messages))
+	for i, m := range messages {
+		index[key{m.Id, m.Event}] = i
+	}
+	for _, m := range received {
+		if i, ok := index[key{m.Id, m.Event}]; ok {
+			messages[i] = m
+			continue
+		}
+		index[key{m.Id, m.Event}] = len(messages)
+		messages = append(messages, m)
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Date < messages[j].Date
+	})
+
+	b, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(config.DataHome()+"/sms", 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(smsCachePath(device), b, 0600)
+}
+
+// Get the latest cached message of every conversation, newest first
+func cachedConversations(device string) ([]internal.GonnectSmsMessage, error) {
+	smsCache.Lock()
+	messages, err := readSmsCache(device)
+	smsCache.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int64]internal.GonnectSmsMessage)
+	for _, m := range messages {
+		// The cache is sorted by date
+		latest[m.ThreadId] = m
+	}
+
+	conversations := make([]internal.GonnectSmsMessage, 0, len(latest))
+	for _, m := range latest {
+		conversations = append(conversations, m)
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].Date > conversations[j].Date
+	})
+	return conversations, nil
+}
+
+// Get the cached messages of a conversation, oldest first
+func cachedConversation(device string, thread int64) ([]internal.GonnectSmsMessage, error) {
+	smsCache.Lock()
+	messages, err := readSmsCache(device)
+	smsCache.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	conversation := make([]internal.GonnectSmsMessage, 0)
+	for _, m := range messages {
+		if m.ThreadId == thread {
+			conversation = append(conversation, m)
+		}
+	}
+	return conversation, nil
+}
@@ -0,0 +1,148 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blennster/gonnect/internal"
+)
+
+func sms(id, thread int64, event int, date int64, body string) internal.GonnectSmsMessage {
+	return internal.GonnectSmsMessage{Id: id, ThreadId: thread, Event: event, Date: date, Body: body}
+}
+
+func TestCacheSmsMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		batches [][]internal.GonnectSmsMessage
+		want    []internal.GonnectSmsMessage
+	}{
+		{
+			name:    "empty",
+			batches: nil,
+			want:    []internal.GonnectSmsMessage{},
+		},
+		{
+			name: "sorted by date",
+			batches: [][]internal.GonnectSmsMessage{
+				{sms(1, 1, 1, 30, "c"), sms(2, 1, 1, 10, "a")},
+				{sms(3, 2, 1, 20, "b")},
+			},
+			want: []internal.GonnectSmsMessage{
+				sms(2, 1, 1, 10, "a"),
+				sms(3, 2, 1, 20, "b"),
+				sms(1, 1, 1, 30, "c"),
+			},
+		},
+		{
+			name: "replaced by id and event",
+			batches: [][]internal.GonnectSmsMessage{
+				{sms(1, 1, 1, 10, "old"), sms(2, 1, 1, 20, "kept")},
+				{sms(1, 1, 1, 10, "new")},
+			},
+			want: []internal.GonnectSmsMessage{
+				sms(1, 1, 1, 10, "new"),
+				sms(2, 1, 1, 20, "kept"),
+			},
+		},
+		{
+			name: "sms and mms with the same id",
+			batches: [][]internal.GonnectSmsMessage{
+				{sms(1, 1, 1, 10, "sms")},
+				{sms(1, 1, 2, 20, "mms")},
+			},
+			want: []internal.GonnectSmsMessage{
+				sms(1, 1, 1, 10, "sms"),
+				sms(1, 1, 2, 20, "mms"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+			for _, batch := range tt.batches {
+				err := cacheSmsMessages("device", batch)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := readSmsCache("device")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachedConversations(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	err := cacheSmsMessages("device", []internal.GonnectSmsMessage{
+		sms(1, 1, 1, 10, "first in 1"),
+		sms(2, 2, 1, 20, "first in 2"),
+		sms(3, 1, 1, 40, "last in 1"),
+		sms(4, 2, 1, 30, "last in 2"),
+		sms(5, 3, 1, 5, "only in 3"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Other devices have their own cache
+	err = cacheSmsMessages("other", []internal.GonnectSmsMessage{sms(6, 1, 1, 50, "other device")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cachedConversations("device")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []internal.GonnectSmsMessage{
+		sms(3, 1, 1, 40, "last in 1"),
+		sms(4, 2, 1, 30, "last in 2"),
+		sms(5, 3, 1, 5, "only in 3"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		thread int64
+		want   []internal.GonnectSmsMessage
+	}{
+		{
+			name:   "thread 1",
+			thread: 1,
+			want:   []internal.GonnectSmsMessage{sms(1, 1, 1, 10, "first in 1"), sms(3, 1, 1, 40, "last in 1")},
+		},
+		{
+			name:   "thread 2",
+			thread: 2,
+			want:   []internal.GonnectSmsMessage{sms(2, 2, 1, 20, "first in 2"), sms(4, 2, 1, 30, "last in 2")},
+		},
+		{
+			name:   "unknown thread",
+			thread: 4,
+			want:   []internal.GonnectSmsMessage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cachedConversation("device", tt.thread)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
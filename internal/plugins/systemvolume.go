@@ -0,0 +1,138 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/audio"
+)
+
+// The system volume plugin lets the other device control the audio outputs
+// of this computer
+type systemVolumePlugin struct{}
+
+// Volumes are sent in percent. Outputs can be louder than this on the desktop,
+// but the device is told they are at the max
+const maxSinkVolume = 100
+
+// Create a new system volume plugin and start sending changes to the outputs
+func NewSystemVolumePlugin(ctx context.Context) *systemVolumePlugin {
+	s := &systemVolumePlugin{}
+	go s.sinkWatcher(ctx)
+
+	return s
+}
+
+// React implements GonnectPlugin.
+func (s *systemVolumePlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectSystemVolumeRequest]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	backend, err := audio.Get()
+	if err != nil {
+		slog.Error("failed to get audio backend", "error", err)
+		return nil
+	}
+
+	req := pkt.Body
+	if req.RequestSinks {
+		list, err := sinkList(backend)
+		if err != nil {
+			slog.Error("failed to list audio outputs", "error", err)
+			return nil
+		}
+		return internal.NewGonnectPacket(list)
+	}
+
+	if req.Name == "" {
+		return nil
+	}
+	// The watcher sends the changes back
+	if req.Volume != nil {
+		err = backend.SetVolume(req.Name, min(max(*req.Volume, 0), maxSinkVolume))
+	}
+	if req.Muted != nil && err == nil {
+		err = backend.SetMuted(req.Name, *req.Muted)
+	}
+	if req.Enabled != nil && *req.Enabled && err == nil {
+		err = backend.SetDefault(req.Name)
+	}
+	if err != nil {
+		slog.Error("failed to change audio output", "sink", req.Name, "error", err)
+	}
+
+	return nil
+}
+
+func sinkList(backend audio.Backend) (internal.GonnectSystemVolume, error) {
+	sinks, err := backend.Sinks()
+	if err != nil {
+		return internal.GonnectSystemVolume{}, err
+	}
+
+	list := internal.GonnectSystemVolume{SinkList: make([]internal.GonnectSystemVolumeSink, 0, len(sinks))}
+	for _, sink := range sinks {
+		list.SinkList = append(list.SinkList, internal.GonnectSystemVolumeSink{
+			Name:        sink.Name,
+			Description: sink.Description,
+			Muted:       sink.Muted,
+			Volume:      min(sink.Volume, maxSinkVolume),
+			MaxVolume:   maxSinkVolume,
+			Enabled:     sink.Default,
+		})
+	}
+	return list, nil
+}
+
+// Send the outputs to the other device whenever they change
+func (s *systemVolumePlugin) sinkWatcher(ctx context.Context) {
+	c := connectionFromContext(ctx)
+	if !c.Supports(internal.GonnectSystemVolumeType) {
+		return
+	}
+
+	backend, err := audio.Get()
+	if err != nil {
+		slog.Warn("not watching audio outputs", "error", err)
+		return
+	}
+	changes, err := backend.Watch(ctx)
+	if err != nil {
+		slog.Warn("not watching audio outputs", "error", err)
+		return
+	}
+
+	var last internal.GonnectSystemVolume
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-changes:
+			if !ok {
+				return
+			}
+		}
+
+		list, err := sinkList(backend)
+		if err != nil {
+			slog.Error("failed to list audio outputs", "error", err)
+			continue
+		}
+		if slices.Equal(list.SinkList, last.SinkList) {
+			continue
+		}
+		last = list
+
+		err = c.Send(internal.NewGonnectPacket(list))
+		if err != nil {
+			slog.Error("failed to send audio outputs", "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package plugins
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/audio"
+)
+
+func TestSinkList(t *testing.T) {
+	backend := audio.NewFake()
+	err := backend.SetVolume("headphones", 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backend.SetMuted("speakers", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := sinkList(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := internal.GonnectSystemVolume{SinkList: []internal.GonnectSystemVolumeSink{
+		{Name: "speakers", Description: "Speakers", Muted: true, Volume: 50, MaxVolume: maxSinkVolume, Enabled: true},
+		// Louder than the max is reported as the max
+		{Name: "headphones", Description: "Headphones", Volume: maxSinkVolume, MaxVolume: maxSinkVolume},
+	}}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("got %+v, want %+v", list, want)
+	}
+}
+
+func TestSinkListDefault(t *testing.T) {
+	backend := audio.NewFake()
+	err := backend.SetDefault("headphones")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := sinkList(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sink := range list.SinkList {
+		if sink.Enabled != (sink.Name == "headphones") {
+			t.Errorf("%s enabled is %v", sink.Name, sink.Enabled)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
+	"github.com/blennster/gonnect/internal/desktop"
+	"github.com/blennster/gonnect/internal/events"
+)
+
+// The telephony plugin tells about calls on the other device and can pause
+// media players while on a call
+type telephonyPlugin struct {
+	// The desktop notification of the current call, 0 if none is shown
+	shown uint32
+	// Bus names of the players paused because of the current call
+	paused []string
+	sync.Mutex
+}
+
+// The button on the notification of a ringing phone
+const muteAction = "Mute"
+
+// Create a new telephony plugin and start listening for the mute button
+func NewTelephonyPlugin(ctx context.Context) *telephonyPlugin {
+	t := &telephonyPlugin{}
+	go t.actionWatcher(ctx)
+
+	return t
+}
+
+// React implements GonnectPlugin.
+func (t *telephonyPlugin) React(ctx context.Context, data []byte) any {
+	var pkt internal.GonnectPacket[internal.GonnectTelephony]
+	err := json.Unmarshal(data, &pkt)
+	if err != nil {
+		panic(err)
+	}
+
+	device := connectionFromContext(ctx).Identity.DeviceId
+	caller := pkt.Body.ContactName
+	if caller == "" && pkt.Body.PhoneNumber != "" {
+		caller = ContactName(device, pkt.Body.PhoneNumber)
+	}
+	if caller == "" {
+		caller = "unknown number"
+	}
+	if caller != pkt.Body.PhoneNumber && pkt.Body.PhoneNumber != "" {
+		caller = fmt.Sprintf("%s (%s)", caller, pkt.Body.PhoneNumber)
+	}
+
+	if pkt.Body.IsCancel {
+		events.Publish(device, "telephony", fmt.Sprintf("%s ended: %s", pkt.Body.Event, caller))
+		if pkt.Body.Event == internal.TelephonyRinging {
+			t.closeNotification()
+		}
+		t.resumeMedia()
+		return nil
+	}
+
+	events.Publish(device, "telephony", fmt.Sprintf("%s: %s", pkt.Body.Event, caller))
+
+	switch pkt.Body.Event {
+	case internal.TelephonyRinging:
+		t.notifyRinging(caller)
+		t.pauseMedia()
+	case internal.TelephonyTalking:
+		t.closeNotification()
+		t.pauseMedia()
+	case internal.TelephonyMissedCall:
+		// Not replaced by the next call, so that it stays until dismissed
+		_, err := desktop.Notify(desktop.Notification{AppName: "gonnect", Title: "Missed call", Body: caller})
+		if err != nil {
+			slog.Error("failed to show missed call notification", "error", err)
+		}
+		t.resumeMedia()
+	default:
+		slog.Debug("unknown telephony event", "event", pkt.Body.Event)
+	}
+
+	return nil
+}
+
+func (t *telephonyPlugin) notifyRinging(caller string) {
+	t.Lock()
+	defer t.Unlock()
+
+	id, err := desktop.Notify(desktop.Notification{
+		AppName:    "gonnect",
+		Title:      "Incoming call",
+		Body:       caller,
+		ReplacesId: t.shown,
+		Actions:    []string{muteAction},
+	})
+	if err != nil {
+		slog.Error("failed to show call notification", "error", err)
+		return
+	}
+	t.shown = id
+}
+
+func (t *telephonyPlugin) closeNotification() {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.shown == 0 {
+		return
+	}
+	err := desktop.CloseNotification(t.shown)
+	if err != nil {
+		slog.Error("failed to close call notification", "error", err)
+	}
+	t.shown = 0
+}
+
+// Pause the players that are playing, if enabled in the settings
+func (t *telephonyPlugin) pauseMedia() {
+	if !config.GetSettings().PauseMediaDuringCalls {
+		return
+	}
+
+	players, err := desktop.Players()
+	if err != nil {
+		slog.Error("failed to list media players", "error", err)
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	for _, p := range players {
+		state, err := p.State()
+		if err != nil || !state.IsPlaying || !state.CanPause {
+			continue
+		}
+
+		err = p.Action("Pause")
+		if err != nil {
+			slog.Error("failed to pause player", "player", p.Name, "error", err)
+			continue
+		}
+		t.paused = append(t.paused, p.BusName)
+	}
+}
+
+// Resume the players paused by pauseMedia
+func (t *telephonyPlugin) resumeMedia() {
+	t.Lock()
+	paused := t.paused
+	t.paused = nil
+	t.Unlock()
+
+	for _, busName := range paused {
+		// The player may have gone away during the call
+		err := desktop.Player{BusName: busName}.Action("Play")
+		if err != nil {
+			slog.Warn("failed to resume player", "player", busName, "error", err)
+		}
+	}
+}
+
+// Mute the ringer when the button on the notification is clicked
+func (t *telephonyPlugin) actionWatcher(ctx context.Context) {
+	actions, err := desktop.WatchActions(ctx)
+	if err != nil {
+		slog.Warn("not watching call notification actions", "error", err)
+		return
+	}
+
+	c := connectionFromContext(ctx)
+	for a := range actions {
+		t.Lock()
+		ours := a.Id == t.shown && a.Action == muteAction
+		t.Unlock()
+		if !ours {
+			continue
+		}
+
+		err := MuteRinger(c.Identity.DeviceId)
+		if err != nil {
+			slog.Error("failed to mute ringer", "error", err)
+		}
+	}
+}
+
+// Mute the ringtone of an incoming call on a device
+func MuteRinger(device string) error {
+	c, err := GetConnection(device)
+	if err != nil {
+		return err
+	}
+	if !c.Supports(internal.GonnectTelephonyRequestMuteType) {
+		return fmt.Errorf("device %q can not mute its ringer", device)
+	}
+
+	return c.Send(internal.NewGonnectPacket(internal.GonnectTelephonyRequestMute{}))
+}
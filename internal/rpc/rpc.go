@@ -8,8 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/blennster/gonnect/internal"
+	"github.com/blennster/gonnect/internal/config"
 	"github.com/blennster/gonnect/internal/discover"
+	"github.com/blennster/gonnect/internal/events"
+	"github.com/blennster/gonnect/internal/plugins"
 	"github.com/blennster/gonnect/internal/security"
+	"github.com/google/uuid"
 )
 
 type rpcctxkey string
@@ -78,6 +83,446 @@ func (*GonnectRpc) GetDevices(_ struct{}, reply *[]string) error {
 	return nil
 }
 
+type SendFileArgs struct {
+	Device string
+	// Paths need to be absolute since the server does not share working directory with the cli
+	Paths []string
+}
+
+// Start sending files to a device, the reply is the ids of the transfers in the same
+// order as the paths
+func (*GonnectRpc) SendFile(args SendFileArgs, reply *[]string) error {
+	slog.Info("rpc send file request", "device", args.Device, "paths", args.Paths)
+
+	ids, err := plugins.SendFiles(args.Device, args.Paths)
+	if err != nil {
+		return err
+	}
+	*reply = ids
+	return nil
+}
+
+func (*GonnectRpc) GetTransfer(id string, reply *plugins.TransferStatus) error {
+	status, err := plugins.GetTransfer(id)
+	if err != nil {
+		return err
+	}
+	*reply = status
+	return nil
+}
+
+type NotificationArgs struct {
+	Device string
+	// The id of the notification on the device
+	Id string
+	// The reply to send
+	Text string
+	// The notification button to press
+	Action string
+}
+
+func (*GonnectRpc) NotifyReply(args NotificationArgs, reply *string) error {
+	slog.Info("rpc notification reply request", "device", args.Device, "id", args.Id)
+
+	err := plugins.ReplyToNotification(args.Device, args.Id, args.Text)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("replied to notification %q", args.Id)
+	return nil
+}
+
+func (*GonnectRpc) NotifyAction(args NotificationArgs, reply *string) error {
+	slog.Info("rpc notification action request", "device", args.Device, "id", args.Id, "action", args.Action)
+
+	err := plugins.ActOnNotification(args.Device, args.Id, args.Action)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("pressed %q on notification %q", args.Action, args.Id)
+	return nil
+}
+
+func (*GonnectRpc) DismissNotification(args NotificationArgs, reply *string) error {
+	slog.Info("rpc notification dismiss request", "device", args.Device, "id", args.Id)
+
+	err := plugins.DismissNotification(args.Device, args.Id)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("dismissed notification %q", args.Id)
+	return nil
+}
+
+type SendNotificationArgs struct {
+	Device string
+	// Shown as the app that sent the notification, defaults to gonnect
+	App   string
+	Title string
+	Text  string
+}
+
+func (*GonnectRpc) SendNotification(args SendNotificationArgs, reply *string) error {
+	slog.Info("rpc send notification request", "device", args.Device, "title", args.Title)
+
+	err := plugins.SendNotification(args.Device, args.App, args.Title, args.Text)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("sent notification to %q", args.Device)
+	return nil
+}
+
+type NotificationQuery struct {
+	Device string
+	// Only get the notifications of this app if set
+	App string
+}
+
+func (*GonnectRpc) GetNotifications(args NotificationQuery, reply *[]plugins.NotificationRecord) error {
+	records, err := plugins.GetNotifications(args.Device, args.App)
+	if err != nil {
+		return err
+	}
+	*reply = records
+	return nil
+}
+
+func (*GonnectRpc) GetBattery(deviceid string, reply *plugins.BatteryStatus) error {
+	status, err := plugins.GetBattery(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = status
+	return nil
+}
+
+func (*GonnectRpc) GetCommands(_ struct{}, reply *map[string]config.Command) error {
+	commands, err := config.GetCommands()
+	if err != nil {
+		return err
+	}
+	*reply = commands
+	return nil
+}
+
+// Add a command that devices can run, the reply is the id of the new command
+func (*GonnectRpc) AddCommand(command config.Command, reply *string) error {
+	slog.Info("rpc add command request", "name", command.Name, "command", command.Command)
+
+	key := uuid.NewString()
+	err := config.UpdateCommands(func(commands map[string]config.Command) error {
+		commands[key] = command
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	plugins.SendCommandLists()
+	*reply = key
+	return nil
+}
+
+func (*GonnectRpc) RemoveCommand(key string, reply *string) error {
+	slog.Info("rpc remove command request", "key", key)
+
+	err := config.UpdateCommands(func(commands map[string]config.Command) error {
+		if _, ok := commands[key]; !ok {
+			return fmt.Errorf("no command with id %q", key)
+		}
+		delete(commands, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	plugins.SendCommandLists()
+	*reply = fmt.Sprintf("removed command %q", key)
+	return nil
+}
+
+type PermissionArgs struct {
+	Device  string
+	Allowed bool
+}
+
+// Allow or deny a device to run the configured commands
+func (*GonnectRpc) SetRunCommandPermission(args PermissionArgs, reply *string) error {
+	slog.Info("rpc run command permission request", "device", args.Device, "allowed", args.Allowed)
+
+	err := security.Devices.SetPermission(args.Device, security.PermissionRunCommand, args.Allowed)
+	if err != nil {
+		return err
+	}
+	// The device does not have to be connected
+	err = plugins.SendCommandList(args.Device)
+	if err != nil {
+		slog.Debug("command list not sent", "device", args.Device, "error", err)
+	}
+	if args.Allowed {
+		*reply = fmt.Sprintf("%q can now run commands", args.Device)
+	} else {
+		*reply = fmt.Sprintf("%q can no longer run commands", args.Device)
+	}
+	return nil
+}
+
+// Allow or deny a device to control the pointer and keyboard
+func (*GonnectRpc) SetRemoteInputPermission(args PermissionArgs, reply *string) error {
+	slog.Info("rpc remote input permission request", "device", args.Device, "allowed", args.Allowed)
+
+	err := plugins.SetRemoteInputPermission(args.Device, args.Allowed)
+	if err != nil {
+		return err
+	}
+	if args.Allowed {
+		*reply = fmt.Sprintf("%q can now control the pointer and keyboard", args.Device)
+	} else {
+		*reply = fmt.Sprintf("%q can no longer control the pointer and keyboard", args.Device)
+	}
+	return nil
+}
+
+func (*GonnectRpc) GetMediaPlayers(deviceid string, reply *[]plugins.RemotePlayer) error {
+	players, err := plugins.GetRemotePlayers(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = players
+	return nil
+}
+
+type MediaArgs struct {
+	Device string
+	// Uses the first player of the device if empty
+	Player string
+	// One of play-pause, next, prev, seek or volume
+	Command string
+	// Seconds to seek or the volume in percent
+	Value int64
+}
+
+func (*GonnectRpc) ControlMedia(args MediaArgs, reply *string) error {
+	slog.Info("rpc media request", "device", args.Device, "player", args.Player, "command", args.Command)
+
+	req := internal.GonnectMprisRequest{Player: args.Player}
+	switch args.Command {
+	case "play-pause":
+		req.Action = "PlayPause"
+	case "next":
+		req.Action = "Next"
+	case "prev":
+		req.Action = "Previous"
+	case "seek":
+		// The protocol uses microseconds
+		req.Seek = args.Value * 1_000_000
+	case "volume":
+		if args.Value < 0 || args.Value > 100 {
+			return fmt.Errorf("volume has to be between 0 and 100")
+		}
+		volume := int(args.Value)
+		req.SetVolume = &volume
+	default:
+		return fmt.Errorf("unknown media command %q", args.Command)
+	}
+
+	err := plugins.ControlRemotePlayer(args.Device, req)
+	if err != nil {
+		return err
+	}
+	*reply = "ok"
+	return nil
+}
+
+type TypeArgs struct {
+	Device string
+	Text   string
+	// A single key such as "enter" or "ctrl+a", sent instead of the text
+	Key string
+}
+
+// Type text or press a key on the remote keyboard of a device
+func (*GonnectRpc) Type(args TypeArgs, reply *plugins.KeyboardStatus) error {
+	var status plugins.KeyboardStatus
+	var err error
+	if args.Key != "" {
+		status, err = plugins.PressKey(args.Device, args.Key)
+	} else {
+		status, err = plugins.TypeText(args.Device, args.Text)
+	}
+	if err != nil {
+		return err
+	}
+	*reply = status
+	return nil
+}
+
+type PingArgs struct {
+	Device string
+	// Shown on the device instead of the default ping text
+	Message string
+}
+
+func (*GonnectRpc) Ping(args PingArgs, reply *string) error {
+	slog.Info("rpc ping request", "device", args.Device)
+
+	err := plugins.Ping(args.Device, args.Message)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("pinged %q", args.Device)
+	return nil
+}
+
+// Ping a device once and measure how long it takes to be acknowledged
+func (*GonnectRpc) MeasureLatency(deviceid string, reply *plugins.Latency) error {
+	latency, err := plugins.MeasureLatency(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = latency
+	return nil
+}
+
+func (*GonnectRpc) GetConversations(deviceid string, reply *[]internal.GonnectSmsMessage) error {
+	conversations, err := plugins.GetConversations(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = conversations
+	return nil
+}
+
+type ConversationArgs struct {
+	Device string
+	Thread int64
+}
+
+func (*GonnectRpc) GetConversation(args ConversationArgs, reply *[]internal.GonnectSmsMessage) error {
+	messages, err := plugins.GetConversation(args.Device, args.Thread)
+	if err != nil {
+		return err
+	}
+	*reply = messages
+	return nil
+}
+
+type SmsArgs struct {
+	Device string
+	// The phone number to send to
+	To   string
+	Text string
+}
+
+func (*GonnectRpc) SendSms(args SmsArgs, reply *string) error {
+	slog.Info("rpc send sms request", "device", args.Device)
+
+	err := plugins.SendSms(args.Device, args.To, args.Text)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("sent message to %s", args.To)
+	return nil
+}
+
+type ContactQuery struct {
+	Device string
+	// Part of a name, phone number or email
+	Query string
+}
+
+func (*GonnectRpc) SearchContacts(args ContactQuery, reply *[]plugins.Contact) error {
+	contacts, err := plugins.SearchContacts(args.Device, args.Query)
+	if err != nil {
+		return err
+	}
+	*reply = contacts
+	return nil
+}
+
+// Get all contacts of a device as a single vcard file
+func (*GonnectRpc) ExportContacts(deviceid string, reply *string) error {
+	export, err := plugins.ExportContacts(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = export
+	return nil
+}
+
+type ContactNamesArgs struct {
+	Device  string
+	Numbers []string
+}
+
+// Look up the contact names of phone numbers, numbers without a contact map to themselves
+func (*GonnectRpc) GetContactNames(args ContactNamesArgs, reply *map[string]string) error {
+	names := make(map[string]string, len(args.Numbers))
+	for _, number := range args.Numbers {
+		names[number] = plugins.ContactName(args.Device, number)
+	}
+	*reply = names
+	return nil
+}
+
+// Lock the screen of a device
+func (*GonnectRpc) LockDevice(deviceid string, reply *string) error {
+	err := plugins.LockDevice(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("locking %q", deviceid)
+	return nil
+}
+
+// Get the last lock state a device sent
+func (*GonnectRpc) GetLockStatus(deviceid string, reply *plugins.LockStatus) error {
+	status, err := plugins.GetLockStatus(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = status
+	return nil
+}
+
+// Mute the ringtone of an incoming call
+func (*GonnectRpc) MuteRinger(deviceid string, reply *string) error {
+	err := plugins.MuteRinger(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = "muted ringer"
+	return nil
+}
+
+// Make a device play a sound so that it can be found
+func (*GonnectRpc) Ring(deviceid string, reply *string) error {
+	slog.Info("rpc ring request", "device", deviceid)
+
+	err := plugins.Ring(deviceid)
+	if err != nil {
+		return err
+	}
+	*reply = fmt.Sprintf("ringing %q", deviceid)
+	return nil
+}
+
+// Stop the sound played when another device is looking for this computer
+func (*GonnectRpc) StopRinging(_ struct{}, reply *string) error {
+	if plugins.StopRinging() {
+		*reply = "stopped ringing"
+	} else {
+		*reply = "not ringing"
+	}
+	return nil
+}
+
+// Get the events newer than the event with id after, use 0 to get all
+func (*GonnectRpc) GetEvents(after int64, reply *[]events.Event) error {
+	*reply = events.Since(after)
+	return nil
+}
+
 type Unsubscribe func()
 
 func WithRpc(ctx context.Context) (context.Context, *GonnectRpc) {
@@ -2,7 +2,10 @@ package security
 
 import (
 	"crypto/x509"
+	"encoding/json"
+	"fmt"
 	"os"
+	"slices"
 
 	"github.com/blennster/gonnect/internal/config"
 )
@@ -53,4 +56,61 @@ func (fileStore) Remove(device string) {
 			panic(err)
 		}
 	}
+
+	// Permissions should not carry over if the device is paired again
+	err = os.Remove(permissionsPath(device))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			panic(err)
+		}
+	}
+}
+
+func permissionsPath(device string) string {
+	return config.DataHome() + "/" + device + ".permissions.json"
+}
+
+func readPermissions(device string) []Permission {
+	permissions := make([]Permission, 0)
+
+	f, err := os.ReadFile(permissionsPath(device))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return permissions
+		}
+		panic(err)
+	}
+
+	err = json.Unmarshal(f, &permissions)
+	if err != nil {
+		// Deny everything rather than guessing
+		return make([]Permission, 0)
+	}
+	return permissions
+}
+
+// SetPermission implements DeviceStore.
+func (f fileStore) SetPermission(device string, permission Permission, allowed bool) error {
+	if f.Get(device) == nil {
+		return fmt.Errorf("device %q is not paired", device)
+	}
+
+	permissions := readPermissions(device)
+	permissions = slices.DeleteFunc(permissions, func(p Permission) bool {
+		return p == permission
+	})
+	if allowed {
+		permissions = append(permissions, permission)
+	}
+
+	b, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(permissionsPath(device), b, 0600)
+}
+
+// HasPermission implements DeviceStore.
+func (fileStore) HasPermission(device string, permission Permission) bool {
+	return slices.Contains(readPermissions(device), permission)
 }
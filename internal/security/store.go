@@ -7,8 +7,22 @@ type DeviceStore interface {
 	Remove(device string)
 	Get(device string) *x509.Certificate
 	// GetDiscoveredDevices() []string
+
+	// Permissions are for features that are off by default since they give the
+	// device control over this computer
+	SetPermission(device string, permission Permission, allowed bool) error
+	HasPermission(device string, permission Permission) bool
 }
 
+type Permission string
+
+const (
+	// Run the commands configured for run command
+	PermissionRunCommand = Permission("runcommand")
+	// Control the pointer and keyboard of this computer
+	PermissionRemoteInput = Permission("remoteinput")
+)
+
 var (
 	// Devices DeviceStore = &inMemoryDeviceStore{devices: make(map[string]*x509.Certificate)}
 
@@ -1,6 +1,7 @@
 package security
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -20,6 +21,28 @@ func GetConfig() *tls.Config {
 
 }
 
+// Get a config that only accepts the certificate that was saved when pairing
+// with device. Used for connections where the device is already known, such as
+// payload transfers
+func GetPinnedConfig(device string) *tls.Config {
+	config := GetConfig()
+	// The certificates are self signed so the chain can not be verified,
+	// instead the certificate is compared with the one saved when pairing
+	config.InsecureSkipVerify = true
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		savedCert := Devices.Get(device)
+		if savedCert == nil {
+			return fmt.Errorf("device %q is not paired", device)
+		}
+		if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], savedCert.Raw) {
+			return fmt.Errorf("certificate mismatch for device %q", device)
+		}
+		return nil
+	}
+
+	return config
+}
+
 func EncodePem(cert x509.Certificate) []byte {
 	return pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",